@@ -0,0 +1,239 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// feedHealth is the outcome of the last fetch of a channel.
+type feedHealth struct {
+	URL       string        `json:"url"`
+	Name      string        `json:"name"`
+	LastFetch time.Time     `json:"lastFetch,omitzero"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	Status    string        `json:"status"` // "ok", "error", or "not fetched"
+	Items     int           `json:"items"`
+	Error     string        `json:"error,omitempty"`
+}
+
+var (
+	healthMu sync.Mutex
+	health   = map[string]feedHealth{} // by channel url
+)
+
+// recordHealth records the fetch of the feed at url that started at start.
+// Only channels in the channel list are recorded.
+func recordHealth(url string, start time.Time, r *rss, err error) {
+	if channelIndex(url) < 0 {
+		return
+	}
+	h := feedHealth{URL: url, LastFetch: start, Duration: time.Since(start), Status: "ok"}
+	if err != nil {
+		h.Status = "error"
+		h.Error = err.Error()
+	} else {
+		h.Items = len(r.Channel.Items)
+	}
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	health[url] = h
+}
+
+// feedHealthList returns the health of every channel, in the order of the
+// channel list. It must not fetch anything, as fetchFeed records its
+// outcome under healthMu.
+func feedHealthList() []feedHealth {
+	list := channelList()
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	out := []feedHealth{}
+	for _, c := range list {
+		h, ok := health[c.URL]
+		if !ok {
+			h = feedHealth{URL: c.URL, Status: "not fetched"}
+		}
+		// The title was fetched when the channels were loaded
+		h.Name = c.Title
+		out = append(out, h)
+	}
+	return out
+}
+
+// serverTime returns t in the server time zone and date format.
+func serverTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	st := dateStyles[serverStyle]
+	return t.In(serverZone).Format(st.Date + " " + st.Time + " MST")
+}
+
+// statusPage is the admin status page. Its forms post to the API, which
+// sends the browser back to the page.
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"time":    serverTime,
+	"channel": channelName,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>rss3270cli</title>
+<style>
+body { font-family: monospace; background: #000; color: #3c3; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.2em 1em 0.2em 0; }
+th { color: #fff; }
+.error { color: #f33; }
+</style>
+</head>
+<body>
+<h1>rss3270cli</h1>
+<h2>Sessions ({{len .Sessions}})</h2>
+<table>
+<tr><th>ID</th><th>Client</th><th>Terminal</th><th>Channel</th><th>Connected</th><th></th></tr>
+{{range .Sessions}}<tr>
+<td>{{.ID}}</td><td>{{.Client}}</td><td>{{.Terminal}}{{if .Device}} {{.Device}}{{end}}</td>
+<td>{{channel .Channel}}</td><td>{{time .Connected}}</td>
+<td><form method="post" action="/api/sessions/{{.ID}}/disconnect"><input type="hidden" name="page" value="1"><button>Disconnect</button></form></td>
+</tr>
+{{end}}</table>
+<h2>Feeds</h2>
+<table>
+<tr><th>Channel</th><th>Last fetch</th><th>Status</th><th>Items</th><th>Error</th><th></th></tr>
+{{range .Feeds}}<tr>
+<td title="{{.URL}}">{{.Name}}</td><td>{{time .LastFetch}}</td>
+<td{{if eq .Status "error"}} class="error"{{end}}>{{.Status}}</td><td>{{.Items}}</td><td class="error">{{.Error}}</td>
+<td><form method="post" action="/api/feeds/refresh"><input type="hidden" name="url" value="{{.URL}}"><input type="hidden" name="page" value="1"><button>Refresh</button></form></td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// checkToken wraps h so that it is only served to requests that give
+// token, either as a bearer token or as the password of basic
+// authentication, which lets a browser ask for it. Posts from pages on
+// other sites are refused, as a browser would send the password along.
+func checkToken(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			_, given, _ = r.BasicAuth()
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="rss3270cli"`)
+			http.Error(w, "token required", http.StatusUnauthorized)
+			return
+		}
+		if origin := r.Header.Get("Origin"); r.Method == http.MethodPost && origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				http.Error(w, "cross-site request refused", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// writeJSON sends v as the JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("admin response", "err", err)
+	}
+}
+
+// done answers an action. A form on the status page is sent back to the
+// page, an API client gets v.
+func done(w http.ResponseWriter, r *http.Request, v any) {
+	if r.PostFormValue("page") != "" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	writeJSON(w, v)
+}
+
+// adminHandler returns the admin interface: the status page on /, and the
+// API under /api.
+func adminHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		data := struct {
+			Sessions []sessionInfo
+			Feeds    []feedHealth
+		}{sessionList(), feedHealthList()}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := statusPage.Execute(w, data); err != nil {
+			slog.Debug("admin status page", "err", err)
+		}
+	})
+	mux.HandleFunc("GET /api/sessions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, sessionList())
+	})
+	mux.HandleFunc("POST /api/sessions/{id}/disconnect", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if !disconnect(id) {
+			http.Error(w, "no session "+id, http.StatusNotFound)
+			return
+		}
+		slog.Info("admin disconnect", "session", id, "client", r.RemoteAddr)
+		done(w, r, map[string]string{"disconnected": id})
+	})
+	mux.HandleFunc("GET /api/feeds", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, feedHealthList())
+	})
+	mux.HandleFunc("POST /api/feeds/refresh", func(w http.ResponseWriter, r *http.Request) {
+		feed := r.FormValue("url")
+		if channelIndex(feed) < 0 {
+			http.Error(w, "not a channel: "+feed, http.StatusNotFound)
+			return
+		}
+		slog.Info("admin refresh", "url", feed, "client", r.RemoteAddr)
+		// The outcome is in the feed health either way
+		refreshFeed(slog.Default(), feed)
+		healthMu.Lock()
+		h := health[feed]
+		healthMu.Unlock()
+		h.Name = channelName(feed)
+		done(w, r, h)
+	})
+	return checkToken(token, mux)
+}
+
+// serveAdmin serves the admin interface at addr, until the listener
+// returned is closed.
+func serveAdmin(addr, token string) (net.Listener, error) {
+	if token == "" {
+		return nil, errors.New("the admin interface needs a token, give -admin-token or set RSS3270_ADMIN_TOKEN")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: adminHandler(token), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, net.ErrClosed) {
+			slog.Error("admin listener", "err", err)
+		}
+	}()
+	slog.Info("serving admin interface", "addr", ln.Addr().String())
+	return ln, nil
+}
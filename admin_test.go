@@ -0,0 +1,62 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestFeedHealthList lists the health of a channel without a name, whose
+// title comes from the feed, while its feed is not in the cache.
+func TestFeedHealthList(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testFeed))
+	}))
+	defer srv.Close()
+
+	channelsMu.Lock()
+	channels = []channel{{URL: srv.URL, Title: "Test"}}
+	channelsMu.Unlock()
+	forgetFeeds(srv.URL)
+	defer func() {
+		channelsMu.Lock()
+		channels = nil
+		channelsMu.Unlock()
+	}()
+
+	list := func() []feedHealth {
+		got := make(chan []feedHealth)
+		go func() { got <- feedHealthList() }()
+		select {
+		case l := <-got:
+			return l
+		case <-time.After(5 * time.Second):
+			t.Fatal("feedHealthList does not return")
+			return nil
+		}
+	}
+
+	l := list()
+	if len(l) != 1 || l[0].Name != "Test" || l[0].Status != "not fetched" {
+		t.Fatalf("before fetching got %+v", l)
+	}
+	if _, err := refreshFeed(slog.Default(), srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	l = list()
+	if len(l) != 1 || l[0].Status != "ok" || l[0].Items != 1 {
+		t.Fatalf("after fetching got %+v", l)
+	}
+}
@@ -0,0 +1,183 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// archivedItem is an item kept in the archive, with the channel it came
+// from, when it was first fetched and its publish date, zero if it has none
+// that can be parsed.
+type archivedItem struct {
+	rssItem
+	Channel   string
+	Seen      time.Time
+	Published time.Time `json:",omitzero"`
+}
+
+// newArchivedItem returns item it of channel, first seen at seen.
+func newArchivedItem(it rssItem, channel string, seen time.Time) archivedItem {
+	a := archivedItem{rssItem: it, Channel: channel, Seen: seen}
+	if t, ok := itemTime(it); ok {
+		a.Published = t.UTC()
+	}
+	return a
+}
+
+// published returns the publish date of the item, or false if it has
+// none.
+func (a archivedItem) published() (time.Time, bool) {
+	if !a.Published.IsZero() {
+		return a.Published, true
+	}
+	// Archived before the publish date was stored
+	return itemTime(a.rssItem)
+}
+
+// when returns the date shown for the item, its publish date, or else when
+// it was first seen.
+func (a archivedItem) when() time.Time {
+	if t, ok := a.published(); ok {
+		return t
+	}
+	return a.Seen
+}
+
+// sortArchived sorts items newest first by their publish date. Items
+// without one come last, newest seen first.
+func sortArchived(items []archivedItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, iok := items[i].published()
+		tj, jok := items[j].published()
+		if iok != jok {
+			return iok
+		}
+		if !iok {
+			return items[i].Seen.After(items[j].Seen)
+		}
+		return ti.After(tj)
+	})
+}
+
+// archive is the database of every item fetched, or nil when disabled.
+// There is a bucket per channel url, keyed by itemKey.
+var archive *bolt.DB
+
+// openArchive opens, or creates, the archive database at path.
+func openArchive(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	archive = db
+	return nil
+}
+
+// archiveItems adds the items of channel not already in the archive. The
+// archive is only written when some are new, as every write is synced to
+// disk.
+func archiveItems(channel string, items []rssItem) {
+	if archive == nil || len(items) == 0 {
+		return
+	}
+	fresh := false
+	archive.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(channel))
+		for _, it := range items {
+			if b == nil || b.Get([]byte(itemKey(it))) == nil {
+				fresh = true
+				break
+			}
+		}
+		return nil
+	})
+	if !fresh {
+		return
+	}
+	now := time.Now().UTC()
+	err := archive.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(channel))
+		if err != nil {
+			return err
+		}
+		for _, it := range items {
+			k := []byte(itemKey(it))
+			if b.Get(k) != nil {
+				continue
+			}
+			v, err := json.Marshal(newArchivedItem(it, channel, now))
+			if err != nil {
+				return err
+			}
+			if err := b.Put(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("archiving items", "url", channel, "err", err)
+	}
+}
+
+// archivedItems returns the archived items of channel, newest first.
+func archivedItems(channel string) ([]archivedItem, error) {
+	out := []archivedItem{}
+	if archive == nil {
+		return out, nil
+	}
+	err := archive.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(channel))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var it archivedItem
+			if err := json.Unmarshal(v, &it); err != nil {
+				return err
+			}
+			out = append(out, it)
+			return nil
+		})
+	})
+	sortArchived(out)
+	return out, err
+}
+
+// findArchived returns the archived items of all channels that match,
+// newest first.
+func findArchived(match func(archivedItem) bool) ([]archivedItem, error) {
+	out := []archivedItem{}
+	if archive == nil {
+		return out, nil
+	}
+	err := archive.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(_ []byte, b *bolt.Bucket) error {
+			return b.ForEach(func(k, v []byte) error {
+				var it archivedItem
+				if err := json.Unmarshal(v, &it); err != nil {
+					return err
+				}
+				if match(it) {
+					out = append(out, it)
+				}
+				return nil
+			})
+		})
+	})
+	sortArchived(out)
+	return out, err
+}
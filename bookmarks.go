@@ -0,0 +1,122 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// bookmark is an item saved to read later, with the channel it is from.
+type bookmark struct {
+	rssItem
+	Channel string    `json:"channel"`
+	Saved   time.Time `json:"saved"`
+	Short   string    `json:"short,omitempty"` // short link, made once when saved
+}
+
+// maxSaved is the number of bookmarks kept in a profile.
+const maxSaved = 200
+
+// exportDir is the directory saved items are exported to, set with
+// -exports. Export is not offered when it is empty.
+var exportDir string
+
+// saveItem adds item of the channel at url to the saved items, and stores
+// the profile. It returns the message to show.
+func (s *session) saveItem(url string, item rssItem) string {
+	k := itemKey(item)
+	for _, b := range s.prof.Saved {
+		if itemKey(b.rssItem) == k {
+			return "Already saved"
+		}
+	}
+	if len(s.prof.Saved) >= maxSaved {
+		return fmt.Sprintf("No more than %d items can be saved", maxSaved)
+	}
+	// A new slice, the old one is shared with the users list
+	saved := make([]bookmark, 0, len(s.prof.Saved)+1)
+	saved = append(saved, s.prof.Saved...)
+	b := bookmark{rssItem: item, Channel: url, Saved: time.Now().UTC()}
+	if link := strings.TrimSpace(item.Link); link != "" {
+		b.Short = shorten(link)
+	}
+	s.prof.Saved = append(saved, b)
+	if err := s.saveProfile(); err != nil {
+		return err.Error()
+	}
+	if s.owner() == "" {
+		return "Saved until you disconnect"
+	}
+	return "Saved"
+}
+
+// deleteSaved removes the saved items at the positions in del.
+func (s *session) deleteSaved(del map[int]bool) error {
+	saved := make([]bookmark, 0, len(s.prof.Saved))
+	for i, b := range s.prof.Saved {
+		if !del[i] {
+			saved = append(saved, b)
+		}
+	}
+	s.prof.Saved = saved
+	return s.saveProfile()
+}
+
+// owner returns the name the profile of the session is kept under, the user
+// ID or the device name, or "" if it is not kept.
+func (s *session) owner() string {
+	switch {
+	case s.userID != "":
+		return s.userID
+	case s.device != "" && devicesFile != "":
+		return s.device
+	}
+	return ""
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// exportSaved writes the saved items as text to a file in exportDir, and
+// returns its name.
+func (s *session) exportSaved() (string, error) {
+	owner := s.owner()
+	if exportDir == "" || owner == "" {
+		return "", fmt.Errorf("saved items can only be exported when signed on")
+	}
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return "", err
+	}
+	name := filepath.Join(exportDir, unsafeName.ReplaceAllString(strings.ToLower(owner), "_")+"-saved.txt")
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "Saved items of %s, %s\n\n", owner, s.dateTime(time.Now()))
+	for _, b := range s.prof.Saved {
+		fmt.Fprintln(w, b.Title)
+		fmt.Fprintf(w, "  %s, saved %s\n", channelName(b.Channel), s.date(b.Saved))
+		if l := strings.TrimSpace(b.Link); l != "" {
+			fmt.Fprintln(w, "  "+l)
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return name, f.Close()
+}
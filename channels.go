@@ -0,0 +1,306 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// channel is one RSS feed from the rssfeed.url file. Name is the optional
+// name given after the url in the file, Title is what is shown on screen.
+// Filter comes from the lines starting with + (include) or - (exclude)
+// that follow the url, Group from the [Name] line before it.
+type channel struct {
+	URL    string
+	Name   string
+	Title  string
+	Filter filter
+	Group  string
+}
+
+// group is a named set of channels. The first channel of a group is its
+// default channel.
+type group struct {
+	Name     string
+	Open     string // what selecting the group shows: "river", "default" or the channel list
+	Channels []channel
+}
+
+var rssFeedFile = "rssfeed.url"
+
+var (
+	channelsMu sync.RWMutex
+	channels   []channel
+	groupOpens map[string]string // group options by group name
+)
+
+// loadChannels reads filename and fetches the title of every channel that
+// has no name of its own.
+func loadChannels(filename string) {
+	list, opens := readRssUrlFile(filename)
+	for i := range list {
+		list[i].Title = channelTitle(slog.Default(), list[i])
+	}
+	channelsMu.Lock()
+	channels = list
+	groupOpens = opens
+	channelsMu.Unlock()
+}
+
+// channelTitle returns the name from the file, or the title of the feed.
+func channelTitle(log *slog.Logger, c channel) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fetchTitle(log, c.URL)
+}
+
+// channelList returns a copy of the current channel list.
+func channelList() []channel {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	out := make([]channel, len(channels))
+	copy(out, channels)
+	return out
+}
+
+// defaultChannel returns the url of the first channel in the list.
+func defaultChannel() string {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	if len(channels) == 0 {
+		return ""
+	}
+	return channels[0].URL
+}
+
+// channelName returns the title of the channel at url, or the url itself
+// for a channel not in the list.
+func channelName(url string) string {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	for _, c := range channels {
+		if c.URL == url {
+			return c.Title
+		}
+	}
+	return url
+}
+
+// channelFilter returns the filter of the channel at url.
+func channelFilter(url string) filter {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	for _, c := range channels {
+		if c.URL == url {
+			return c.Filter
+		}
+	}
+	return filter{}
+}
+
+// channelGroups splits list into its groups, in the order they first
+// appear, and the channels in no group.
+func channelGroups(list []channel) (groups []group, ungrouped []channel) {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	index := make(map[string]int)
+	for _, c := range list {
+		if c.Group == "" {
+			ungrouped = append(ungrouped, c)
+			continue
+		}
+		i, ok := index[c.Group]
+		if !ok {
+			i = len(groups)
+			index[c.Group] = i
+			groups = append(groups, group{Name: c.Group, Open: groupOpens[c.Group]})
+		}
+		groups[i].Channels = append(groups[i].Channels, c)
+	}
+	return groups, ungrouped
+}
+
+// groupStart returns the position of the first channel of group name, or
+// -1.
+func groupStart(name string) int {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	for i, c := range channels {
+		if c.Group == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// channelGroup returns the group of the channel at url.
+func channelGroup(url string) string {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	for _, c := range channels {
+		if c.URL == url {
+			return c.Group
+		}
+	}
+	return ""
+}
+
+// channelIndex returns the position of url in the channel list, or -1.
+func channelIndex(url string) int {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	for i, c := range channels {
+		if c.URL == url {
+			return i
+		}
+	}
+	return -1
+}
+
+// addChannel appends a channel and saves the list.
+func addChannel(log *slog.Logger, url, name string) error {
+	c := channel{URL: url, Name: name}
+	c.Title = channelTitle(log, c)
+
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	for _, old := range channels {
+		if old.URL == url {
+			return fmt.Errorf("channel already exists")
+		}
+	}
+	channels = append(channels, c)
+	return saveChannels()
+}
+
+// deleteChannel removes channel i and saves the list.
+func deleteChannel(i int) error {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	if i < 0 || i >= len(channels) {
+		return fmt.Errorf("no channel %d", i)
+	}
+	if len(channels) == 1 {
+		return fmt.Errorf("the last channel can not be deleted")
+	}
+	channels = append(channels[:i], channels[i+1:]...)
+	return saveChannels()
+}
+
+// moveChannel moves channel i to position j and saves the list. Moving a
+// channel to position 0 makes it the default channel.
+func moveChannel(i, j int) error {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	if i < 0 || i >= len(channels) {
+		return fmt.Errorf("no channel %d", i)
+	}
+	if j < 0 || j >= len(channels) || i == j {
+		return nil
+	}
+	c := channels[i]
+	channels = append(channels[:i], channels[i+1:]...)
+	channels = append(channels[:j], append([]channel{c}, channels[j:]...)...)
+	return saveChannels()
+}
+
+// saveChannels writes the channel list back to rssFeedFile. The caller must
+// hold channelsMu.
+func saveChannels() error {
+	tmp := rssFeedFile + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	group := ""
+	for _, c := range channels {
+		if c.Group != group {
+			group = c.Group
+			if o := groupOpens[group]; o != "" {
+				fmt.Fprintf(w, "\n[%s] %s\n", group, o)
+			} else {
+				fmt.Fprintf(w, "\n[%s]\n", group)
+			}
+		}
+		if c.Name != "" {
+			fmt.Fprintf(w, "%s %s\n", c.URL, c.Name)
+		} else {
+			fmt.Fprintln(w, c.URL)
+		}
+		for _, p := range c.Filter.Include {
+			fmt.Fprintf(w, "  + %s\n", p)
+		}
+		for _, p := range c.Filter.Exclude {
+			fmt.Fprintf(w, "  - %s\n", p)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, rssFeedFile)
+}
+
+// adminNets are the client networks allowed to manage channels.
+var adminNets []*net.IPNet
+
+// parseAdminNets parses a comma separated list of IP addresses and CIDR
+// networks.
+func parseAdminNets(list string) ([]*net.IPNet, error) {
+	out := []*net.IPNet{}
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if strings.Contains(s, ":") {
+				s += "/128"
+			} else {
+				s += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// isAdmin reports whether the client on conn may manage channels.
+func isAdmin(conn net.Conn) bool {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range adminNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // zones for -tz and profiles on hosts without a zone database
+)
+
+// newestFirst is set with -newest, to sort headlines by date unless the
+// profile says otherwise.
+var newestFirst bool
+
+// dateStyle is a way of writing dates and times, by its layouts for
+// time.Format.
+type dateStyle struct {
+	Date string
+	Time string
+}
+
+// dateStyles are the date formats that can be chosen, by name.
+var dateStyles = map[string]dateStyle{
+	"ISO": {Date: "2006-01-02", Time: "15:04"},
+	"EU":  {Date: "02.01.2006", Time: "15:04"},
+	"US":  {Date: "01/02/2006", Time: "3:04PM"},
+}
+
+// The time zone and date format used unless the profile has its own, set
+// with -tz and -datefmt.
+var (
+	serverZone  = time.UTC
+	serverStyle = "ISO"
+)
+
+// setServerTime sets the default time zone and date format.
+func setServerTime(zone, style string) error {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return err
+	}
+	style = strings.ToUpper(style)
+	if _, ok := dateStyles[style]; !ok {
+		return fmt.Errorf("unknown date format %s, use ISO, EU or US", style)
+	}
+	serverZone, serverStyle = loc, style
+	return nil
+}
+
+// zone returns the time zone of the session.
+func (s *session) zone() *time.Location {
+	if s.prof.Zone != "" {
+		if loc, err := time.LoadLocation(s.prof.Zone); err == nil {
+			return loc
+		}
+	}
+	return serverZone
+}
+
+// style returns the date format of the session.
+func (s *session) style() dateStyle {
+	if st, ok := dateStyles[s.prof.DateFormat]; ok {
+		return st
+	}
+	return dateStyles[serverStyle]
+}
+
+// clock returns the time of day of t, with the zone, e.g. "15:04 CET".
+func (s *session) clock(t time.Time) string {
+	return t.In(s.zone()).Format(s.style().Time + " MST")
+}
+
+// date returns the date of t.
+func (s *session) date(t time.Time) string {
+	return t.In(s.zone()).Format(s.style().Date)
+}
+
+// dateTime returns the date and time of t, with the zone.
+func (s *session) dateTime(t time.Time) string {
+	return t.In(s.zone()).Format(s.style().Date + " " + s.style().Time + " MST")
+}
+
+// dateFormats are the layouts tried for the date of an item, once any
+// weekday has been removed. RSS uses RFC 822 and Atom RFC 3339, but feeds
+// often leave out the seconds, spell out the month, use a two digit year,
+// leave out the zone or the colon of the offset, or use RFC 850 or the
+// format of C's asctime. Fractions of a second are accepted by every
+// layout with seconds.
+var dateFormats = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2 January 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04 MST",
+	"2-Jan-06 15:04:05 MST",
+	"2-Jan-06 15:04:05 -0700",
+	"2-Jan-2006 15:04:05 MST",
+	"2-Jan-2006 15:04:05 -0700",
+	"Jan 2 15:04:05 2006",
+	"Jan 2 15:04:05 MST 2006",
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// zoneOffsets are the zone names seen in feeds that time.Parse does not
+// know the offset of.
+var zoneOffsets = map[string]int{
+	"EST": -5 * 3600, "EDT": -4 * 3600,
+	"CST": -6 * 3600, "CDT": -5 * 3600,
+	"MST": -7 * 3600, "MDT": -6 * 3600,
+	"PST": -8 * 3600, "PDT": -7 * 3600,
+	"CET": 1 * 3600, "CEST": 2 * 3600,
+	"BST": 1 * 3600,
+}
+
+// parseDate parses the date of an item.
+func parseDate(s string) (time.Time, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+	// Drop the weekday, it is often wrong or not in English
+	if i := strings.Index(s, ","); i >= 0 && i <= 10 {
+		s = strings.TrimSpace(s[i+1:])
+	} else if f := strings.Fields(s); len(f) > 1 && len(f[0]) >= 3 && !strings.ContainsAny(f[0], "0123456789") {
+		if _, err := time.Parse("Jan", f[0][:3]); err != nil {
+			s = strings.Join(f[1:], " ")
+		}
+	}
+	if strings.HasSuffix(s, " UT") || strings.HasSuffix(s, " Z") {
+		s = s[:strings.LastIndex(s, " ")] + " UTC"
+	}
+
+	for _, f := range dateFormats {
+		t, err := time.Parse(f, s)
+		if err != nil {
+			continue
+		}
+		if name, off := t.Zone(); off == 0 {
+			if o, ok := zoneOffsets[name]; ok {
+				y, mo, d := t.Date()
+				t = time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, o))
+			}
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// itemTime returns the publish date of it, or false if it has none that
+// can be parsed.
+func itemTime(it rssItem) (time.Time, bool) {
+	if strings.TrimSpace(it.PubDate) == "" {
+		return time.Time{}, false
+	}
+	return parseDate(it.PubDate)
+}
+
+// sortNewest sorts items newest first. Items without a date keep their
+// order after the dated ones.
+func sortNewest(items []rssItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, iok := itemTime(items[i])
+		tj, jok := itemTime(items[j])
+		if iok != jok {
+			return iok
+		}
+		return ti.After(tj)
+	})
+}
+
+// age returns how long ago t was, in the largest unit that fits: "5m",
+// "3h", "2d". It is empty for an unknown time.
+func age(t time.Time, ok bool) string {
+	if !ok {
+		return ""
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
+
+// itemAge returns the age of it, padded to the width of the age column.
+func itemAge(it rssItem) string {
+	return fmt.Sprintf("%-4s", age(itemTime(it)))
+}
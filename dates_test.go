@@ -0,0 +1,68 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	utc := func(s string) time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	tests := []struct {
+		in   string
+		want time.Time // zero when the date cannot be parsed
+	}{
+		// RFC 822 and the ways feeds get it wrong
+		{"Sun, 19 Oct 2025 14:33:00 +0200", utc("2025-10-19T12:33:00Z")},
+		{"Sun, 19 Oct 2025 14:33:00 GMT", utc("2025-10-19T14:33:00Z")},
+		{"Sun, 19 Oct 2025 14:33:00 EDT", utc("2025-10-19T18:33:00Z")},
+		{"Sun, 19 Oct 2025 14:33 +0200", utc("2025-10-19T12:33:00Z")},
+		{"Sunday, 19 October 2025 14:33:00 UT", utc("2025-10-19T14:33:00Z")},
+		{"Søn, 19 Oct 2025 14:33:00 +0000", utc("2025-10-19T14:33:00Z")},
+		{"19 Oct 25 14:33:00 Z", utc("2025-10-19T14:33:00Z")},
+		{"  Sun,  19 Oct 2025\n 14:33:00 +0000 ", utc("2025-10-19T14:33:00Z")},
+		// RFC 850 and asctime
+		{"Sunday, 19-Oct-25 14:33:00 GMT", utc("2025-10-19T14:33:00Z")},
+		{"Monday, 02-Jan-06 15:04:05 MST", utc("2006-01-02T22:04:05Z")},
+		{"Sun Oct 19 14:33:00 2025", utc("2025-10-19T14:33:00Z")},
+		// ISO 8601 and RFC 3339
+		{"2025-10-19T14:33:00Z", utc("2025-10-19T14:33:00Z")},
+		{"2025-10-19T14:33:00+02:00", utc("2025-10-19T12:33:00Z")},
+		{"2025-10-19T14:33:00+0200", utc("2025-10-19T12:33:00Z")},
+		{"2025-10-19T14:33:00.123+0200", utc("2025-10-19T12:33:00.123Z")},
+		{"2025-10-19T14:33+02:00", utc("2025-10-19T12:33:00Z")},
+		{"2025-10-19T14:33:00", utc("2025-10-19T14:33:00Z")},
+		{"2025-10-19 14:33:00", utc("2025-10-19T14:33:00Z")},
+		{"2025-10-19", utc("2025-10-19T00:00:00Z")},
+		// Not dates
+		{"", time.Time{}},
+		{"yesterday", time.Time{}},
+		{"19/10/2025", time.Time{}},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.in)
+		if tt.want.IsZero() {
+			if ok {
+				t.Errorf("parseDate(%q) = %v, want no date", tt.in, got)
+			}
+			continue
+		}
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, %v, want %v", tt.in, got, ok, tt.want)
+		}
+	}
+}
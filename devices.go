@@ -0,0 +1,177 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/racingmars/go3270"
+)
+
+// devicesFile is the file given with -devices. Profiles of terminals
+// without a signed on user are only kept when it is set.
+var devicesFile string
+
+var (
+	devicesMu sync.Mutex
+	devices   = map[string]profile{}
+)
+
+// Telnet commands and the TN3270E option, RFC 854 and RFC 2355.
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+
+	optTN3270E = 40
+
+	tn3270eConnect    = 1
+	tn3270eDeviceType = 2
+	tn3270eRequest    = 7
+	tn3270eSend       = 8
+)
+
+// negotiateDevice asks the emulator for the TN3270E device (LU) name it
+// connects with, and returns it, or "" if it has none or does not speak
+// TN3270E. go3270 only speaks plain TN3270, so TN3270E is turned off again
+// once the name is known, and the emulator falls back to TN3270 when
+// go3270.NegotiateTelnet runs next.
+func negotiateDevice(conn net.Conn) (string, error) {
+	if _, err := conn.Write([]byte{telnetIAC, telnetDO, optTN3270E}); err != nil {
+		return "", err
+	}
+	cmd, opt, _, err := readTelnet(conn)
+	if err != nil {
+		return "", err
+	}
+	if cmd != telnetWILL || opt != optTN3270E {
+		return "", nil
+	}
+
+	send := []byte{telnetIAC, telnetSB, optTN3270E, tn3270eSend, tn3270eDeviceType, telnetIAC, telnetSE}
+	if _, err := conn.Write(send); err != nil {
+		return "", err
+	}
+	cmd, opt, sb, err := readTelnet(conn)
+	if err != nil {
+		return "", err
+	}
+	name := ""
+	// DEVICE-TYPE REQUEST <device-type> [CONNECT <device-name>]
+	if cmd == telnetSB && opt == optTN3270E && len(sb) > 2 &&
+		sb[0] == tn3270eDeviceType && sb[1] == tn3270eRequest {
+		if i := bytes.IndexByte(sb[2:], tn3270eConnect); i >= 0 {
+			name = string(sb[2+i+1:])
+		}
+	}
+
+	if _, err := conn.Write([]byte{telnetIAC, telnetDONT, optTN3270E}); err != nil {
+		return "", err
+	}
+	for cmd != telnetWONT || opt != optTN3270E {
+		if cmd, opt, _, err = readTelnet(conn); err != nil {
+			return "", err
+		}
+	}
+	return strings.ToUpper(strings.TrimSpace(name)), nil
+}
+
+// readTelnet reads from r up to the next telnet command, and returns the
+// command, its option, and for a subnegotiation the bytes between IAC SB
+// <option> and IAC SE. It reads a byte at a time, so nothing meant for
+// go3270 is read ahead.
+func readTelnet(r io.Reader) (cmd, opt byte, sb []byte, err error) {
+	b := make([]byte, 1)
+	next := func() byte {
+		if err == nil {
+			_, err = io.ReadFull(r, b)
+		}
+		return b[0]
+	}
+	for err == nil {
+		if next() != telnetIAC {
+			continue
+		}
+		switch cmd = next(); cmd {
+		case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+			opt = next()
+			return cmd, opt, nil, err
+		case telnetSB:
+			opt = next()
+			for err == nil {
+				c := next()
+				if c == telnetIAC {
+					// IAC SE ends it, IAC IAC is a 255
+					if c = next(); c == telnetSE {
+						return cmd, opt, sb, err
+					}
+				}
+				sb = append(sb, c)
+			}
+		}
+	}
+	return 0, 0, nil, err
+}
+
+// terminalType returns the terminal type negotiated with the emulator.
+func terminalType(devinfo go3270.DevInfo) string {
+	if devinfo == nil {
+		return ""
+	}
+	return devinfo.TerminalType()
+}
+
+// loadDevices reads the devices file. A missing file is an empty list.
+func loadDevices() error {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	content, err := os.ReadFile(devicesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, &devices)
+}
+
+// deviceProfile returns the profile kept for device name.
+func deviceProfile(name string) profile {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	return devices[name].clone()
+}
+
+// saveDeviceProfile stores the profile of device name in the devices file.
+func saveDeviceProfile(name string, p profile) error {
+	devicesMu.Lock()
+	defer devicesMu.Unlock()
+	devices[name] = p
+	content, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := devicesFile + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, devicesFile)
+}
@@ -0,0 +1,74 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+// emulator answers the TN3270E negotiation like a client asking for
+// request, a DEVICE-TYPE REQUEST, or refusing TN3270E if request is nil.
+func emulator(t *testing.T, conn net.Conn, request []byte) {
+	defer conn.Close()
+	expect := func(want []byte) bool {
+		got := make([]byte, len(want))
+		if _, err := io.ReadFull(conn, got); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("emulator got %v, want %v", got, want)
+			return false
+		}
+		return true
+	}
+	if !expect([]byte{telnetIAC, telnetDO, optTN3270E}) {
+		return
+	}
+	if request == nil {
+		conn.Write([]byte{telnetIAC, telnetWONT, optTN3270E})
+		return
+	}
+	conn.Write([]byte{telnetIAC, telnetWILL, optTN3270E})
+	if !expect([]byte{telnetIAC, telnetSB, optTN3270E, tn3270eSend, tn3270eDeviceType, telnetIAC, telnetSE}) {
+		return
+	}
+	sb := append([]byte{telnetIAC, telnetSB, optTN3270E, tn3270eDeviceType, tn3270eRequest}, request...)
+	conn.Write(append(sb, telnetIAC, telnetSE))
+	if !expect([]byte{telnetIAC, telnetDONT, optTN3270E}) {
+		return
+	}
+	conn.Write([]byte{telnetIAC, telnetWONT, optTN3270E})
+}
+
+func TestNegotiateDevice(t *testing.T) {
+	tests := []struct {
+		name    string
+		request []byte
+		want    string
+	}{
+		{"no TN3270E", nil, ""},
+		{"no device name", []byte("IBM-3278-2-E"), ""},
+		{"device name", append([]byte("IBM-3278-2-E\x01"), "lu0042"...), "LU0042"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer server.Close()
+			go emulator(t, client, tt.request)
+			got, err := negotiateDevice(server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
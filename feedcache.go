@@ -0,0 +1,79 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// feedCacheTTL is how long a fetched feed is used before it is fetched
+// again, set with -feed-cache. Every screen shows the channel title and its
+// items, and the river shows every channel, so without the cache a feed
+// would be fetched several times for each screen, and once per user. Enter
+// on the headline screens and the river refreshes past the cache. 0 turns
+// the cache off.
+var feedCacheTTL = time.Minute
+
+type cachedFeed struct {
+	feed    *rss
+	fetched time.Time
+}
+
+var (
+	feedCacheMu sync.Mutex
+	feedCache   = map[string]cachedFeed{}
+)
+
+// getFeed returns the feed at url, fetched within feedCacheTTL. Errors are
+// not cached. The items of a feed are archived when it is fetched.
+func getFeed(log *slog.Logger, url string) (*rss, error) {
+	feedCacheMu.Lock()
+	c, ok := feedCache[url]
+	feedCacheMu.Unlock()
+	hit := ok && time.Since(c.fetched) < feedCacheTTL
+	countCache(hit)
+	if hit {
+		return c.feed, nil
+	}
+
+	r, err := fetchFeed(log, url)
+	if err != nil {
+		return nil, err
+	}
+	// Only what was fetched now can be new to the archive
+	archiveItems(url, titledItems(r))
+	feedCacheMu.Lock()
+	feedCache[url] = cachedFeed{feed: r, fetched: time.Now()}
+	for u, c := range feedCache {
+		if time.Since(c.fetched) >= feedCacheTTL {
+			delete(feedCache, u)
+		}
+	}
+	feedCacheMu.Unlock()
+	return r, nil
+}
+
+// forgetFeeds drops the feeds at urls from the cache, so they are fetched
+// again the next time they are shown.
+func forgetFeeds(urls ...string) {
+	feedCacheMu.Lock()
+	defer feedCacheMu.Unlock()
+	for _, url := range urls {
+		delete(feedCache, url)
+	}
+}
+
+// refreshFeed fetches the items of the feed at url again, past the cache.
+func refreshFeed(log *slog.Logger, url string) ([]rssItem, error) {
+	forgetFeeds(url)
+	return fetchItems(log, url)
+}
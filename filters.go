@@ -0,0 +1,123 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// filter selects the items of a channel that are shown. A pattern is a
+// keyword, matched anywhere in the title or description ignoring case, or a
+// regular expression between slashes, e.g. /^(?i)sport/.
+type filter struct {
+	Include []string `json:"include,omitempty"` // show only items matching one of these
+	Exclude []string `json:"exclude,omitempty"` // hide items matching any of these
+}
+
+// compilePattern returns the function testing text against pattern.
+func compilePattern(pattern string) (func(string) bool, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+	word := strings.ToLower(pattern)
+	return func(text string) bool {
+		return strings.Contains(strings.ToLower(text), word)
+	}, nil
+}
+
+// compileAll compiles a list of patterns.
+func compileAll(patterns []string) ([]func(string) bool, error) {
+	out := make([]func(string) bool, 0, len(patterns))
+	for _, p := range patterns {
+		m, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// check returns an error for the first pattern of f that does not compile.
+func (f filter) check() error {
+	if _, err := compileAll(f.Include); err != nil {
+		return err
+	}
+	_, err := compileAll(f.Exclude)
+	return err
+}
+
+// applyFilters returns the items passing all filters, and the number of
+// items hidden. Patterns that do not compile are ignored.
+func applyFilters(items []rssItem, filters ...filter) ([]rssItem, int) {
+	type compiled struct{ include, exclude []func(string) bool }
+	var cs []compiled
+	for _, f := range filters {
+		var c compiled
+		for _, p := range f.Include {
+			if m, err := compilePattern(p); err == nil {
+				c.include = append(c.include, m)
+			}
+		}
+		for _, p := range f.Exclude {
+			if m, err := compilePattern(p); err == nil {
+				c.exclude = append(c.exclude, m)
+			}
+		}
+		if len(c.include) > 0 || len(c.exclude) > 0 {
+			cs = append(cs, c)
+		}
+	}
+	if len(cs) == 0 {
+		return items, 0
+	}
+
+	anyMatch := func(ms []func(string) bool, text string) bool {
+		for _, m := range ms {
+			if m(text) {
+				return true
+			}
+		}
+		return false
+	}
+	out := make([]rssItem, 0, len(items))
+	for _, it := range items {
+		text := it.Title + " " + plainText(it.Description)
+		keep := true
+		for _, c := range cs {
+			if (len(c.include) > 0 && !anyMatch(c.include, text)) || anyMatch(c.exclude, text) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, it)
+		}
+	}
+	return out, len(items) - len(out)
+}
+
+// hiddenNote returns the field telling how many items the filters hid, to
+// be placed at the end of the separator on row 2.
+func hiddenNote(hidden int) []go3270.Field {
+	if hidden == 0 {
+		return nil
+	}
+	text := fmt.Sprintf(" %d hidden by filters ", hidden)
+	return []go3270.Field{{Row: 2, Col: 79 - len(text), Content: text, Color: go3270.Yellow}}
+}
@@ -0,0 +1,144 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// key is a function key offered on a screen.
+type key struct {
+	AID      go3270.AID
+	Label    string // text in the footer
+	Help     string // text on the help screen
+	Validate bool   // field rules are checked before the key is accepted
+}
+
+// keyMap is the set of keys a transaction accepts. The same map drives the
+// keys passed to go3270, the footer and the help screen, so a key is never
+// shown without being handled or accepted without being shown.
+type keyMap struct {
+	Screen string
+	Keys   []key
+}
+
+// The keys that mean the same on every screen.
+var (
+	keyHelp     = key{AID: go3270.AIDPF1, Label: "Help", Help: "Show the keys for this screen"}
+	keyReturn   = key{AID: go3270.AIDPF3, Label: "Return", Help: "Return to the previous screen"}
+	keyChannels = key{AID: go3270.AIDPF4, Label: "Channels", Help: "Select another channel"}
+	keyRecent   = key{AID: go3270.AIDPF5, Label: "Recent", Help: "Select one of the channels viewed before"}
+	keyExit     = key{AID: go3270.AIDPF9, Label: "Exit", Help: "End the session"}
+	keySearch   = key{AID: go3270.AIDPF11, Label: "Search", Help: "Search the items of all channels"}
+)
+
+// keyOrder is the order keys are shown in, and their names.
+var keyOrder = []struct {
+	AID  go3270.AID
+	Name string
+}{
+	{go3270.AIDEnter, "Enter"},
+	{go3270.AIDPF1, "F1"},
+	{go3270.AIDPF2, "F2"},
+	{go3270.AIDPF3, "F3"},
+	{go3270.AIDPF4, "F4"},
+	{go3270.AIDPF5, "F5"},
+	{go3270.AIDPF6, "F6"},
+	{go3270.AIDPF7, "F7"},
+	{go3270.AIDPF8, "F8"},
+	{go3270.AIDPF9, "F9"},
+	{go3270.AIDPF10, "F10"},
+	{go3270.AIDPF11, "F11"},
+	{go3270.AIDPF12, "F12"},
+	{go3270.AIDPF13, "F13"},
+}
+
+// with returns a copy of km with k added.
+func (km keyMap) with(k ...key) keyMap {
+	keys := make([]key, 0, len(km.Keys)+len(k))
+	keys = append(keys, km.Keys...)
+	km.Keys = append(keys, k...)
+	return km
+}
+
+// sorted returns the keys of km in keyOrder, with their names.
+func (km keyMap) sorted() (names []string, keys []key) {
+	for _, o := range keyOrder {
+		for _, k := range km.Keys {
+			if k.AID == o.AID {
+				names = append(names, o.Name)
+				keys = append(keys, k)
+			}
+		}
+	}
+	return names, keys
+}
+
+// pfkeys returns the keys for which go3270 validates the fields.
+func (km keyMap) pfkeys() []go3270.AID {
+	out := []go3270.AID{}
+	for _, k := range km.Keys {
+		if k.Validate {
+			out = append(out, k.AID)
+		}
+	}
+	return out
+}
+
+// exitkeys returns the keys accepted without validating the fields.
+func (km keyMap) exitkeys() []go3270.AID {
+	out := []go3270.AID{}
+	for _, k := range km.Keys {
+		if !k.Validate {
+			out = append(out, k.AID)
+		}
+	}
+	return out
+}
+
+// footer returns the separator line and the key labels for rows 22 and 23.
+// When the keys do not fit in row 23 they take both rows instead of the
+// separator. Keys that still do not fit are left out, they are listed on
+// the help screen.
+func (km keyMap) footer() []go3270.Field {
+	names, keys := km.sorted()
+	width := func(i int) int { return len(names[i]) + 1 + len(keys[i].Label) }
+	total := 0
+	for i := range keys {
+		total += width(i) + 2
+	}
+
+	fields := []go3270.Field{}
+	row := 23
+	if total-2 > 79 {
+		row = 22
+	} else {
+		fields = append(fields,
+			go3270.Field{Row: 22, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
+		)
+	}
+	col := 0
+	for i, k := range keys {
+		if col+width(i) > 79 {
+			if row == 23 {
+				break
+			}
+			row, col = 23, 0
+		}
+		fields = append(fields,
+			go3270.Field{Row: row, Col: col, Content: names[i], Color: go3270.Turquoise, Intense: true},
+			go3270.Field{Row: row, Col: col + len(names[i]) + 1, Content: k.Label, Color: go3270.Blue, Intense: true},
+		)
+		col += width(i) + 2
+	}
+	return fields
+}
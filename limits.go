@@ -0,0 +1,116 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/racingmars/go3270"
+)
+
+// The limits set with -max-sessions, -max-per-ip, -negotiate-timeout and
+// -idle-timeout. Zero means no limit.
+var (
+	maxSessions      int
+	maxPerIP         int
+	negotiateTimeout time.Duration
+	idleTimeout      time.Duration
+)
+
+// idleGrace is how long the idle warning is shown before disconnecting.
+const idleGrace = time.Minute
+
+// admit records conn as open, unless that would exceed the limits. It
+// returns why the connection is refused, or "".
+func admit(conn net.Conn) string {
+	host := remoteHost(conn)
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	if maxSessions > 0 && len(conns) >= maxSessions {
+		return fmt.Sprintf("%d sessions open", len(conns))
+	}
+	if maxPerIP > 0 {
+		n := 0
+		for c := range conns {
+			if remoteHost(c) == host {
+				n++
+			}
+		}
+		if n >= maxPerIP {
+			return fmt.Sprintf("%d sessions open from %s", n, host)
+		}
+	}
+	conns[conn] = nil
+	return ""
+}
+
+// remoteHost returns the IP address conn comes from.
+func remoteHost(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
+
+// idleConn is a connection whose reads time out when the terminal has sent
+// nothing for timeout. idle is set when that happens.
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+	idle    atomic.Bool
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	if c.timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	}
+	n, err := c.Conn.Read(p)
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		c.idle.Store(true)
+	}
+	return n, err
+}
+
+// idleWarning tells the terminal it is about to be disconnected, and
+// reports whether a key was pressed within idleGrace.
+func idleWarning(c *idleConn) bool {
+	screen := go3270.Screen{
+		{Row: 0, Col: 0, Content: padCenter("Are you still there?", 79), Color: go3270.White, Intense: true},
+		{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		{Row: 4, Col: 10, Content: fmt.Sprintf("Nothing has been entered for %s.", idleTimeout), Color: go3270.Yellow, Intense: true},
+		{Row: 6, Col: 10, Content: fmt.Sprintf("Press Enter within %s to continue, or the session ends.", idleGrace), Color: go3270.Turquoise},
+	}
+	var keys []go3270.AID
+	for _, o := range keyOrder {
+		keys = append(keys, o.AID)
+	}
+
+	timeout := c.timeout
+	c.timeout = idleGrace
+	c.idle.Store(false)
+	defer func() { c.timeout = timeout }()
+	_, err := go3270.HandleScreen(screen, nil, nil, keys, nil, "", 0, 0, c)
+	return err == nil
+}
+
+// showIdleEnd tells the terminal the session ended for being idle.
+func showIdleEnd(conn net.Conn) {
+	screen := go3270.Screen{
+		{Row: 0, Col: 0, Content: padCenter("Disconnected", 79), Color: go3270.White, Intense: true},
+		{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		{Row: 4, Col: 10, Content: "The session ended because nothing was entered.", Color: go3270.Yellow, Intense: true},
+	}
+	go3270.ShowScreenOpts(screen, nil, conn, go3270.ScreenOpts{NoResponse: true})
+}
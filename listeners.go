@@ -0,0 +1,148 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handshakeTimeout bounds the TLS handshake of a new connection.
+const handshakeTimeout = 10 * time.Second
+
+// tlsConfig returns the TLS configuration for the certificate and key
+// files. With a client CA file, clients must present a certificate signed
+// by it.
+func tlsConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + clientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// handshake completes the TLS handshake of a connection from a TLS
+// listener, and returns the attributes to log about it. Plain connections
+// are left alone.
+func handshake(conn net.Conn) ([]any, error) {
+	tc, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil, nil
+	}
+	tc.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer tc.SetDeadline(time.Time{})
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	state := tc.ConnectionState()
+	info := []any{"tls", tls.VersionName(state.Version), "cipher", tls.CipherSuiteName(state.CipherSuite)}
+	if len(state.PeerCertificates) > 0 {
+		info = append(info, "client_cert", state.PeerCertificates[0].Subject.CommonName)
+	}
+	return info, nil
+}
+
+// listenFlag collects the addresses given with -listen, which can be
+// repeated.
+type listenFlag []string
+
+func (l *listenFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listenFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// listen opens a listener for addr, host:port or tls://host:port. cfg is
+// the TLS configuration, nil when no certificate is given.
+func listen(addr string, cfg *tls.Config) (net.Listener, error) {
+	if a, ok := strings.CutPrefix(addr, "tls://"); ok {
+		if cfg == nil {
+			return nil, errors.New("TLS listener " + a + " needs -tls-cert and -tls-key")
+		}
+		ln, err := tls.Listen("tcp", a, cfg)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("listening", "addr", ln.Addr().String(), "tls", true)
+		return ln, nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("listening", "addr", ln.Addr().String(), "tls", false)
+	return ln, nil
+}
+
+// systemdListeners returns the sockets passed by systemd socket activation,
+// or none if the server was not started that way. Sockets named tls in the
+// FileDescriptorName= of the socket unit are served with TLS.
+func systemdListeners(cfg *tls.Config) ([]net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	const firstFD = 3
+	out := []net.Listener{}
+	for i := 0; i < n; i++ {
+		name := "systemd"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(firstFD+i), name)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("systemd socket %s: %v", name, err)
+		}
+		if name == "tls" {
+			if cfg == nil {
+				return nil, errors.New("systemd socket tls needs -tls-cert and -tls-key")
+			}
+			ln = tls.NewListener(ln, cfg)
+		}
+		slog.Info("listening", "addr", ln.Addr().String(), "tls", name == "tls", "systemd", name)
+		out = append(out, ln)
+	}
+	return out, nil
+}
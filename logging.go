@@ -0,0 +1,58 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// setupLogging makes the default logger write format, text or json, to
+// stderr, leaving out events below level. Times are in the server time
+// zone.
+func setupLogging(format, level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("unknown log level %s, use debug, info, warn or error", level)
+	}
+	opts := &slog.HandlerOptions{
+		Level: lvl,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				a.Value = slog.TimeValue(a.Value.Time().In(serverZone))
+			}
+			return a
+		},
+	}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "text":
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("unknown log format %s, use text or json", format)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// lastSessionID numbers the sessions since the server started.
+var lastSessionID atomic.Uint64
+
+// newSessionID returns the ID of a new session, attached to everything
+// logged about it.
+func newSessionID() string {
+	return strconv.FormatUint(lastSessionID.Add(1), 10)
+}
@@ -0,0 +1,304 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/racingmars/go3270"
+	"github.com/subosito/shorturl"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency
+// histograms.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram counts observations in latencyBuckets.
+type histogram struct {
+	Buckets []uint64 // observations up to each bound, not cumulative
+	Sum     float64
+	Count   uint64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if h.Buckets == nil {
+		h.Buckets = make([]uint64, len(latencyBuckets))
+	}
+	v := d.Seconds()
+	for i, b := range latencyBuckets {
+		if v <= b {
+			h.Buckets[i]++
+			break
+		}
+	}
+	h.Sum += v
+	h.Count++
+}
+
+// clone returns a copy of h that shares no buckets with it.
+func (h histogram) clone() histogram {
+	h.Buckets = slices.Clone(h.Buckets)
+	return h
+}
+
+// feedStats are the metrics of fetching one channel.
+type feedStats struct {
+	Fetches histogram
+	Errors  uint64
+}
+
+var (
+	metricsMu        sync.Mutex
+	connectionsTotal uint64
+	refusedTotal     uint64
+	screensServed    = map[string]uint64{}     // by transaction
+	feedFetches      = map[string]*feedStats{} // by channel url
+	cacheHits        uint64
+	cacheMisses      uint64
+	shortenLatency   histogram
+	shortenErrors    uint64
+)
+
+// countConnection counts a connection accepted, or refused by admit.
+func countConnection(refused bool) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	connectionsTotal++
+	if refused {
+		refusedTotal++
+	}
+}
+
+// countScreen counts a run of transaction tx.
+func countScreen(tx go3270.Tx) {
+	name := runtime.FuncForPC(reflect.ValueOf(tx).Pointer()).Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	screensServed[name]++
+}
+
+// observeFetch records a fetch of the feed at url. Feeds that are not in
+// the channel list, such as those tried before they are added, are counted
+// together as "other", so users cannot add a label for every url.
+func observeFetch(url string, d time.Duration, err error) {
+	if channelIndex(url) < 0 {
+		url = "other"
+	}
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	f := feedFetches[url]
+	if f == nil {
+		f = &feedStats{}
+		feedFetches[url] = f
+	}
+	f.Fetches.observe(d)
+	if err != nil {
+		f.Errors++
+	}
+}
+
+// countCache counts a lookup in the feed cache.
+func countCache(hit bool) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if hit {
+		cacheHits++
+	} else {
+		cacheMisses++
+	}
+}
+
+// shorten returns the short url of link, or link itself when the link
+// shortener fails.
+func shorten(link string) string {
+	start := time.Now()
+	u, err := shorturl.Shorten(link, "tinyurl")
+	metricsMu.Lock()
+	shortenLatency.observe(time.Since(start))
+	if err != nil {
+		shortenErrors++
+	}
+	metricsMu.Unlock()
+	if err != nil {
+		return link
+	}
+	return string(u)
+}
+
+// metricsSnapshot is a copy of the metrics, so they can be written to a
+// slow scraper without holding metricsMu.
+type metricsSnapshot struct {
+	connections   uint64
+	refused       uint64
+	screens       map[string]uint64
+	feeds         map[string]feedStats
+	cacheHits     uint64
+	cacheMisses   uint64
+	shorten       histogram
+	shortenErrors uint64
+}
+
+// snapshotMetrics returns a copy of the metrics.
+func snapshotMetrics() metricsSnapshot {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	m := metricsSnapshot{
+		connections:   connectionsTotal,
+		refused:       refusedTotal,
+		screens:       maps.Clone(screensServed),
+		feeds:         make(map[string]feedStats, len(feedFetches)),
+		cacheHits:     cacheHits,
+		cacheMisses:   cacheMisses,
+		shorten:       shortenLatency.clone(),
+		shortenErrors: shortenErrors,
+	}
+	for url, f := range feedFetches {
+		m.feeds[url] = feedStats{Fetches: f.Fetches.clone(), Errors: f.Errors}
+	}
+	return m
+}
+
+// writeMetrics writes all metrics in the Prometheus text format.
+func writeMetrics(w io.Writer) {
+	active, negotiating := 0, 0
+	connsMu.Lock()
+	for _, s := range conns {
+		if s == nil {
+			negotiating++
+		} else {
+			active++
+		}
+	}
+	connsMu.Unlock()
+
+	m := snapshotMetrics()
+
+	fmt.Fprintln(w, "# HELP rss3270_sessions_active Terminals connected.")
+	fmt.Fprintln(w, "# TYPE rss3270_sessions_active gauge")
+	fmt.Fprintf(w, "rss3270_sessions_active %d\n", active)
+	fmt.Fprintln(w, "# HELP rss3270_sessions_negotiating Connections still negotiating the terminal type.")
+	fmt.Fprintln(w, "# TYPE rss3270_sessions_negotiating gauge")
+	fmt.Fprintf(w, "rss3270_sessions_negotiating %d\n", negotiating)
+	fmt.Fprintln(w, "# HELP rss3270_connections_total Connections accepted since the server started.")
+	fmt.Fprintln(w, "# TYPE rss3270_connections_total counter")
+	fmt.Fprintf(w, "rss3270_connections_total %d\n", m.connections)
+	fmt.Fprintln(w, "# HELP rss3270_connections_refused_total Connections refused by the session limits.")
+	fmt.Fprintln(w, "# TYPE rss3270_connections_refused_total counter")
+	fmt.Fprintf(w, "rss3270_connections_refused_total %d\n", m.refused)
+
+	fmt.Fprintln(w, "# HELP rss3270_screens_total Screens served, by transaction.")
+	fmt.Fprintln(w, "# TYPE rss3270_screens_total counter")
+	for _, name := range sortedKeys(m.screens) {
+		fmt.Fprintf(w, "rss3270_screens_total{screen=\"%s\"} %d\n", name, m.screens[name])
+	}
+
+	fmt.Fprintln(w, "# HELP rss3270_feed_fetch_seconds Time taken to fetch a feed, by channel.")
+	fmt.Fprintln(w, "# TYPE rss3270_feed_fetch_seconds histogram")
+	for _, url := range sortedKeys(m.feeds) {
+		writeHistogram(w, "rss3270_feed_fetch_seconds", `channel="`+labelValue.Replace(url)+`"`, m.feeds[url].Fetches)
+	}
+	fmt.Fprintln(w, "# HELP rss3270_feed_fetch_errors_total Failed fetches of a feed, by channel.")
+	fmt.Fprintln(w, "# TYPE rss3270_feed_fetch_errors_total counter")
+	for _, url := range sortedKeys(m.feeds) {
+		fmt.Fprintf(w, "rss3270_feed_fetch_errors_total{channel=\"%s\"} %d\n", labelValue.Replace(url), m.feeds[url].Errors)
+	}
+
+	fmt.Fprintln(w, "# HELP rss3270_feed_cache_hits_total Feeds served from the feed cache.")
+	fmt.Fprintln(w, "# TYPE rss3270_feed_cache_hits_total counter")
+	fmt.Fprintf(w, "rss3270_feed_cache_hits_total %d\n", m.cacheHits)
+	fmt.Fprintln(w, "# HELP rss3270_feed_cache_misses_total Feeds fetched because they were not in the feed cache.")
+	fmt.Fprintln(w, "# TYPE rss3270_feed_cache_misses_total counter")
+	fmt.Fprintf(w, "rss3270_feed_cache_misses_total %d\n", m.cacheMisses)
+	fmt.Fprintln(w, "# HELP rss3270_feed_cache_hit_ratio Share of feed lookups served from the cache.")
+	fmt.Fprintln(w, "# TYPE rss3270_feed_cache_hit_ratio gauge")
+	ratio := 0.0
+	if m.cacheHits+m.cacheMisses > 0 {
+		ratio = float64(m.cacheHits) / float64(m.cacheHits+m.cacheMisses)
+	}
+	fmt.Fprintf(w, "rss3270_feed_cache_hit_ratio %g\n", ratio)
+
+	fmt.Fprintln(w, "# HELP rss3270_shorten_seconds Time taken by the link shortener.")
+	fmt.Fprintln(w, "# TYPE rss3270_shorten_seconds histogram")
+	writeHistogram(w, "rss3270_shorten_seconds", "", m.shorten)
+	fmt.Fprintln(w, "# HELP rss3270_shorten_errors_total Links the link shortener failed to shorten.")
+	fmt.Fprintln(w, "# TYPE rss3270_shorten_errors_total counter")
+	fmt.Fprintf(w, "rss3270_shorten_errors_total %d\n", m.shortenErrors)
+}
+
+// labelValue escapes a label value for the text format.
+var labelValue = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeHistogram writes the series of histogram h, with the labels given.
+func writeHistogram(w io.Writer, name, labels string, h histogram) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cum uint64
+	for i, b := range latencyBuckets {
+		if h.Buckets != nil {
+			cum += h.Buckets[i]
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%g\"} %d\n", name, labels, sep, b, cum)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.Count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %g\n", name, labels, h.Sum)
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.Count)
+}
+
+// sortedKeys returns the keys of m in order, so the metrics are always
+// written in the same order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// serveMetrics serves the metrics on /metrics at addr, until the listener
+// returned is closed.
+func serveMetrics(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w)
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, net.ErrClosed) {
+			slog.Error("metrics listener", "err", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", ln.Addr().String())
+	return ln, nil
+}
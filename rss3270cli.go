@@ -10,30 +10,55 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/xml"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	go3270 "github.com/racingmars/go3270"
-	"github.com/subosito/shorturl"
 )
 
 type rss struct {
+	XMLName xml.Name
 	Channel struct {
 		Title string    `xml:"title"`
 		Items []rssItem `xml:"item"`
 	} `xml:"channel"`
+	// Atom feeds have no channel element, title and entries sit directly
+	// below the root.
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+type atomEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Links     []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
 }
 type rssItem struct {
-	Title string `xml:"title"`
-	Link  string `xml:"link"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
 }
 
 const (
@@ -42,43 +67,159 @@ const (
 )
 
 var layout = go3270.Screen{}
-var rssFeeds = readRssUrlFile("rssfeed.url")
-var defrssFeedURL = rssFeeds[0]
-var rssChannels [20][2]string
 
 func main() {
 
-	//rssChannels := make([][]string,0)
+	//Define command line arguments
+	port := flag.String("port", "7300", "Listen on port, empty for no plain listener, unless -listen is given")
+	tlsPort := flag.String("tls-port", "7301", "Listen on port for TLS connections, when -tls-cert is given and -listen is not")
+	var listenAddrs listenFlag
+	flag.Var(&listenAddrs, "listen", "Listen on address host:port, or tls://host:port for TLS, can be repeated")
+	tlsCert := flag.String("tls-cert", "", "Certificate file for TLS connections")
+	tlsKey := flag.String("tls-key", "", "Key file of the TLS certificate")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA file that TLS clients must present a certificate from")
+	flag.IntVar(&maxSessions, "max-sessions", 100, "Most sessions open at the same time, 0 for no limit")
+	flag.IntVar(&maxPerIP, "max-per-ip", 5, "Most sessions open from one IP address, 0 for no limit")
+	flag.DurationVar(&negotiateTimeout, "negotiate-timeout", 10*time.Second, "Time a new connection gets to negotiate the terminal type")
+	flag.DurationVar(&idleTimeout, "idle-timeout", 15*time.Minute, "Time without input before a session is warned and then ended, 0 for none")
+	logFormat := flag.String("log-format", "text", "Log format, text or json")
+	logLevel := flag.String("log-level", "info", "Least important events logged, debug, info, warn or error")
+	metricsAddr := flag.String("metrics", "", "Serve Prometheus metrics over HTTP on host:port, e.g. :9300, empty to disable")
+	adminAddr := flag.String("admin-http", "", "Serve the admin status page and API over HTTP on host:port, e.g. 127.0.0.1:9301, empty to disable")
+	adminToken := flag.String("admin-token", "", "Token the admin interface asks for, or set RSS3270_ADMIN_TOKEN")
+	flag.DurationVar(&feedCacheTTL, "feed-cache", time.Minute, "Time a fetched feed is shown before it is fetched again, 0 to always fetch")
+	drain := flag.Duration("drain", 30*time.Second, "Time sessions get to end when the server shuts down")
+	admins := flag.String("admins", "127.0.0.1,::1", "Comma separated IP addresses or networks allowed to manage channels")
+	flag.StringVar(&usersFile, "users", "", "Users file, enables sign on")
+	flag.StringVar(&devicesFile, "devices", "", "File to remember terminals by TN3270E device name")
+	flag.BoolVar(&newestFirst, "newest", false, "Sort headlines newest first, unless a user profile says otherwise")
+	tz := flag.String("tz", "UTC", "Time zone of the log, and of users without their own, e.g. Europe/Copenhagen")
+	datefmt := flag.String("datefmt", "ISO", "Date format of users without their own, ISO, EU or US")
+	flag.StringVar(&exportDir, "exports", "exports", "Directory saved items are exported to, empty to disable")
+	archiveFile := flag.String("archive", "rss3270cli.db", "Database keeping every item fetched, empty to disable")
+	adduser := flag.String("adduser", "", "Add a user, or change its password, in the users file and exit")
+	useradmin := flag.Bool("useradmin", false, "The user added with -adduser may manage channels")
+	flag.Parse()
+	if *adminToken == "" {
+		// Not the flag default, -h would print it
+		*adminToken = os.Getenv("RSS3270_ADMIN_TOKEN")
+	}
 
-	for i, url := range rssFeeds {
-		ct := fetchTitle(url)
-		if ct != "" {
-			rssChannels[i][0] = ct
-		} else {
-			rssChannels[i][0] = "No Title found"
+	if usersFile != "" {
+		if err := loadUsers(); err != nil {
+			panic(err)
 		}
-		rssChannels[i][1] = url
-		i++
+	}
+	if devicesFile != "" {
+		if err := loadDevices(); err != nil {
+			panic(err)
+		}
+	}
+	if *adduser != "" {
+		if usersFile == "" {
+			fmt.Println("-adduser needs -users")
+			os.Exit(1)
+		}
+		fmt.Print("Password for " + *adduser + ": ")
+		password, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err := setPassword(*adduser, strings.TrimSpace(password), *useradmin); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	//Define command line arguments
-	port := flag.String("port", "7300", "Listen on port")
-	flag.Parse()
-	listenAddr := ":" + *port
+	var err error
+	if adminNets, err = parseAdminNets(*admins); err != nil {
+		panic(err)
+	}
+	if err := setServerTime(*tz, *datefmt); err != nil {
+		panic(err)
+	}
+	if err := setupLogging(*logFormat, *logLevel); err != nil {
+		panic(err)
+	}
+	if *archiveFile != "" {
+		if err := openArchive(*archiveFile); err != nil {
+			panic(err)
+		}
+	}
+	loadChannels(rssFeedFile)
+	if len(channelList()) == 0 {
+		panic("no channels found in " + rssFeedFile)
+	}
 
-	ln, err := net.Listen("tcp", listenAddr)
+	var cfg *tls.Config
+	if *tlsCert != "" {
+		if cfg, err = tlsConfig(*tlsCert, *tlsKey, *tlsClientCA); err != nil {
+			panic(err)
+		}
+	}
+	// Sockets from systemd, the addresses given with -listen, or else the
+	// ports
+	listeners, err := systemdListeners(cfg)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Println("LISTENING ON PORT " + listenAddr + " FOR CONNECTIONS")
-	fmt.Println("Press Ctrl-C to end server.")
-	for {
-		conn, err := ln.Accept()
+	if len(listenAddrs) == 0 && len(listeners) == 0 {
+		if *port != "" {
+			listenAddrs = append(listenAddrs, ":"+*port)
+		}
+		if cfg != nil {
+			listenAddrs = append(listenAddrs, "tls://:"+*tlsPort)
+		}
+	}
+	for _, addr := range listenAddrs {
+		ln, err := listen(addr, cfg)
+		if err != nil {
+			panic(err)
+		}
+		listeners = append(listeners, ln)
+	}
+	if len(listeners) == 0 {
+		panic("no listeners, give -port, -listen or -tls-cert")
+	}
+	if *metricsAddr != "" {
+		ln, err := serveMetrics(*metricsAddr)
+		if err != nil {
+			panic(err)
+		}
+		// Metrics are served until the sessions have drained
+		defer ln.Close()
+	}
+	if *adminAddr != "" {
+		ln, err := serveAdmin(*adminAddr, *adminToken)
 		if err != nil {
 			panic(err)
 		}
-		go handle(conn)
+		defer ln.Close()
+	}
+	slog.Info("server started, press Ctrl-C to end it", "channels", len(channelList()))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var wg sync.WaitGroup
+	for _, ln := range listeners {
+		wg.Add(1)
+		go func(ln net.Listener) {
+			defer wg.Done()
+			serve(ln)
+		}(ln)
+	}
+
+	<-ctx.Done()
+	slog.Info("shutting down", "sessions", openSessions())
+	for _, ln := range listeners {
+		ln.Close()
+	}
+	wg.Wait()
+	shutdown(*drain)
+	if archive != nil {
+		if err := archive.Close(); err != nil {
+			slog.Error("closing archive", "err", err)
+		}
 	}
+	slog.Info("server stopped")
 }
 
 // handle is the handler for individual user connections.
@@ -86,45 +227,126 @@ func handle(conn net.Conn) {
 	defer conn.Close()
 
 	//Log the client IP connection
-	connectTime := time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
-	clientAddress := conn.RemoteAddr().String()
-	fmt.Println(connectTime + " - connection from " + clientAddress)
+	s := newSession(conn.RemoteAddr().String(), defaultChannel())
+	s.log.Info("connect", "client", s.addr)
+	tlsInfo, err := handshake(conn)
+	if err != nil {
+		s.log.Warn("TLS handshake failed", "err", err)
+		return
+	}
 	// Always begin new connection by negotiating the telnet options
+	if negotiateTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(negotiateTimeout))
+	}
+	device := ""
+	if devicesFile != "" {
+		// The device name is only needed to remember the terminal
+		if device, err = negotiateDevice(conn); err != nil {
+			s.log.Warn("TN3270E negotiation failed", "err", err)
+			return
+		}
+	}
 	devinfo, err := go3270.NegotiateTelnet(conn)
 	if err != nil {
-		fmt.Println(err)
+		s.log.Warn("telnet negotiation failed", "err", err)
 		return
 	}
-
-	err = go3270.RunTransactions(conn, devinfo, rssfeed, defrssFeedURL)
+	conn.SetDeadline(time.Time{})
+
+	s.identify(devinfo, device)
+	register(conn, s)
+	s.log.Info("terminal", append([]any{"type", s.terminal, "device", s.device, "channel", s.url}, tlsInfo...)...)
+	ic := &idleConn{Conn: conn, timeout: idleTimeout}
+	err = go3270.RunTransactions(ic, devinfo, guard(s.first()), s)
+	for err != nil && ic.idle.Load() && !shuttingDown.Load() {
+		// Warn, and show the screen again if the terminal answers
+		if !idleWarning(ic) {
+			s.log.Info("idle timeout", "after", idleTimeout)
+			showIdleEnd(conn)
+			err = nil
+			break
+		}
+		ic.idle.Store(false)
+		err = go3270.RunTransactions(ic, devinfo, guard(s.cur), s)
+	}
 	if err != nil {
-		fmt.Println(err)
+		s.log.Warn("session ended", "err", err)
+	}
+	if err := s.saveProfile(); err != nil {
+		s.log.Error("saving profile", "err", err)
 	}
-	disconnectconnectTime := time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
-	fmt.Println(disconnectconnectTime + " - disconnect from " + clientAddress)
+	s.log.Info("disconnect", "duration", time.Since(s.connected).Round(time.Second))
 }
 
-func fetchTitle(url string) string {
+// fetchFeed retrieves url and decodes it as an RSS or Atom feed. The fetch
+// is logged to log, the logger of the session that asked for it.
+func fetchFeed(log *slog.Logger, url string) (r *rss, err error) {
+	start := time.Now()
+	defer func() {
+		observeFetch(url, time.Since(start), err)
+		recordHealth(url, start, r, err)
+		if err != nil {
+			log.Warn("fetch failed", "url", url, "duration", time.Since(start), "err", err)
+		} else {
+			log.Debug("fetch", "url", url, "duration", time.Since(start), "items", len(r.Channel.Items))
+		}
+	}()
+
 	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
+	return decodeFeed(resp.Body)
+}
+
+// decodeFeed decodes an RSS or Atom document. Atom entries are copied into
+// the RSS channel so callers only deal with one format.
+func decodeFeed(body io.Reader) (*rss, error) {
 	var r rss
-	if err := xml.NewDecoder(resp.Body).Decode(&r); err != nil {
-		fmt.Println(err)
+	if err := xml.NewDecoder(body).Decode(&r); err != nil {
+		return nil, err
+	}
+	switch r.XMLName.Local {
+	case "rss", "feed":
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed")
+	}
+	if r.Channel.Title == "" && len(r.Channel.Items) == 0 {
+		r.Channel.Title = r.Title
+		for _, e := range r.Entries {
+			it := rssItem{Title: e.Title, Description: e.Summary, GUID: e.ID, PubDate: e.Published}
+			if it.PubDate == "" {
+				it.PubDate = e.Updated
+			}
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					it.Link = l.Href
+					break
+				}
+			}
+			r.Channel.Items = append(r.Channel.Items, it)
+		}
+	}
+	return &r, nil
+}
+
+func fetchTitle(log *slog.Logger, url string) string {
+	r, err := getFeed(log, url)
+	if err != nil {
+		return "No Title found"
 	}
 	title := replaceUnhandledChar(r.Channel.Title)
 
@@ -134,102 +356,99 @@ func fetchTitle(url string) string {
 	return title
 }
 
-func fetchHeadlines(url string, limit int) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+// itemKey identifies an item, by its guid or else its link or title.
+func itemKey(it rssItem) string {
+	if g := strings.TrimSpace(it.GUID); g != "" {
+		return g
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	if l := strings.TrimSpace(it.Link); l != "" {
+		return l
 	}
+	return it.Title
+}
 
-	var r rss
-	if err := xml.NewDecoder(resp.Body).Decode(&r); err != nil {
+// fetchItems returns the items with a title from the feed at url.
+func fetchItems(log *slog.Logger, url string) ([]rssItem, error) {
+	r, err := getFeed(log, url)
+	if err != nil {
 		return nil, err
 	}
-	out := make([]string, 0, limit)
+	return titledItems(r), nil
+}
+
+// titledItems returns the items of feed r that have a title.
+func titledItems(r *rss) []rssItem {
+	all := make([]rssItem, 0, len(r.Channel.Items))
 	for _, it := range r.Channel.Items {
-		t := strings.TrimSpace(it.Title)
-		rpl := replaceUnhandledChar(t)
+		it.Title = replaceUnhandledChar(strings.TrimSpace(it.Title))
 
-		if rpl != "" {
-			out = append(out, rpl)
-			if len(out) >= limit {
-				break
-			}
+		if it.Title != "" {
+			all = append(all, it)
 		}
 	}
-	if len(out) == 0 {
-		out = []string{"(No headlines found)"}
-	}
-	return out, nil
+	return all
 }
 
-func fetchHeadlineLinks(url string, limit int) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+// headlines returns the lines shown on the rssfeed screen, one per item.
+func headlines(items []rssItem) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, itemAge(it)+it.Title)
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	if len(out) == 0 {
+		out = []string{"(No headlines found)"}
 	}
+	return out
+}
 
-	var r rss
-	if err := xml.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return nil, err
-	}
-	out := make([]string, 0, limit)
-	for _, it := range r.Channel.Items {
-		t := strings.TrimSpace(it.Title)
-		rpl := replaceUnhandledChar(t)
-		strleng := 45
-		var str string
+// headlineLinks returns the lines shown on the rssfeedlinks screen, one per
+// item, with the start of the title followed by a short url to the article.
+func headlineLinks(items []rssItem) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		strleng := 41
+		str := itemAge(it) + it.Title
 
 		//add the url link for the item to the output
 		l := strings.TrimSpace(it.Link)
 		if l != "" {
-			provider := "tinyurl"
-			u, err := shorturl.Shorten(it.Link, provider)
-			if err == nil {
-				if len([]rune(t)) > strleng {
-					str = rpl[0:strleng] + " " + string(u)
-				} else {
-					str = padRight(rpl, strleng) + " " + string(u)
-				}
+			if u := shorten(l); u != l {
+				str = itemAge(it) + padRight(it.Title, strleng) + " " + u
 			}
-		} else {
-			str = rpl
 		}
+		out = append(out, str)
+	}
+	if len(out) == 0 {
+		out = []string{"(No headlines found)"}
+	}
+	return out
+}
 
-		if str != "" {
-			out = append(out, str)
-			if len(out) >= limit {
+// headlineRows lays out numbered lines from row 3 down to row 20, wrapped
+// at 80 columns. Lines marked in read are shown dimmed. rows maps each
+// screen row used to the index of its line, so a cursor position can be
+// turned back into a selection.
+func headlineRows(lines []string, read map[int]bool) (fields []go3270.Field, rows map[int]int) {
+	rows = make(map[int]int)
+	row := 3
+	for i, h := range lines {
+		for _, line := range wrap80(fmt.Sprintf("%2d. %s", i+1, strings.TrimSpace(h)), 80) {
+			if row >= 21 { // leave space for messages and the footer
 				break
 			}
+			color := go3270.White
+			if read[i] {
+				color = go3270.Blue
+			}
+			fields = append(fields, go3270.Field{Row: row, Col: 0, Content: line, Color: color})
+			rows[row] = i
+			row++
+		}
+		if row >= 21 {
+			break
 		}
 	}
-	if len(out) == 0 {
-		out = []string{"(No headlines found)"}
-	}
-	return out, nil
+	return fields, rows
 }
 
 func wrap80(s string, width int) []string {
@@ -263,6 +482,38 @@ func max80(s string, width int) []string {
 	return lines
 }
 
+// choiceRules returns the validation rules for a "choice" field that
+// selects one of n numbered entries. A blank choice is allowed.
+func choiceRules(n int) go3270.Rules {
+	errorText := fmt.Sprintf("Please enter a number from 0 to %d", n-1)
+	if n == 0 {
+		errorText = "There is nothing to select"
+	}
+	return go3270.Rules{
+		"choice": {
+			Validator: func(input string) bool {
+				input = strings.TrimSpace(input)
+				if input == "" {
+					return true
+				}
+				i, err := strconv.Atoi(input)
+				return err == nil && i >= 0 && i < n
+			},
+			ErrorText: errorText,
+		},
+	}
+}
+
+// parseChoice returns the number in a "choice" field validated by
+// choiceRules, or -1 if it is blank.
+func parseChoice(input string) int {
+	i, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return -1
+	}
+	return i
+}
+
 func padRight(s string, w int) string {
 	if len(s) >= w {
 		return s[:w]
@@ -317,21 +568,56 @@ func replaceUnhandledChar(s string) string {
 	return line
 }
 
-func readRssUrlFile(filename string) []string {
+// readRssUrlFile reads the channel list. Each line holds the url of a feed,
+// optionally followed by a name to show instead of the feed title. A line
+// [Name] starts a group, optionally followed by what selecting the group
+// shows, and [] ends it. The options of the groups are returned by name.
+func readRssUrlFile(filename string) ([]channel, map[string]string) {
 	content, err := os.ReadFile(filename)
 	lines := strings.Split(string(content), "\n")
-	out := []string{}
+	out := []channel{}
+	opens := make(map[string]string)
+	group := ""
 
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("reading channels", "err", err)
 	}
-	// Only return lines with 'http'
+	// Only return lines starting with 'http', and the filter lines
+	// starting with '+' or '-' that follow them
 	for _, line := range lines {
-		if strings.Count(line, "http") == 1 {
-			out = append(out, line)
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
 		}
+		if (f[0] == "+" || f[0] == "-") && len(f) > 1 && len(out) > 0 {
+			c := &out[len(out)-1]
+			pattern := strings.TrimSpace(strings.TrimSpace(line)[1:])
+			if _, err := compilePattern(pattern); err != nil {
+				slog.Warn("ignoring filter", "url", c.URL, "err", err)
+				continue
+			}
+			if f[0] == "+" {
+				c.Filter.Include = append(c.Filter.Include, pattern)
+			} else {
+				c.Filter.Exclude = append(c.Filter.Exclude, pattern)
+			}
+			continue
+		}
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "[") {
+			if end := strings.Index(t, "]"); end > 0 {
+				group = strings.TrimSpace(t[1:end])
+				if group != "" {
+					opens[group] = strings.ToLower(strings.TrimSpace(t[end+1:]))
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(f[0], "http") {
+			continue
+		}
+		out = append(out, channel{URL: f[0], Name: strings.Join(f[1:], " "), Group: group})
 	}
 
-	return out
+	return out, opens
 
 }
@@ -0,0 +1,139 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// archiveRows is the number of archived items shown per page.
+const archiveRows = 18
+
+// keyArchive is offered on the headline screens when the archive is open.
+var keyArchive = key{AID: go3270.AIDPF6, Label: "Archive", Help: "Browse every item archived for the channel"}
+
+// archiveKeys are the keys accepted on the rssarchive screen.
+var archiveKeys = keyMap{Screen: "Archive", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Read", Help: "Read the item under the cursor", Validate: true},
+	keyHelp,
+	keyReturn,
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show newer items"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show older items"},
+	keyExit,
+}}
+
+func rssarchive(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := archiveKeys
+
+	items, err := archivedItems(s.url)
+	if s.page >= len(items) {
+		s.page = 0
+	}
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Archive"
+	header := padCenter(title, 80)
+
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: "Channel ", Color: go3270.Blue, Intense: true},
+		go3270.Field{Row: 1, Col: 8, Content: channelName(s.url), Color: go3270.Turquoise},
+		go3270.Field{Row: 1, Col: 62, Content: fmt.Sprintf("%6d items", len(items)), Color: go3270.Blue},
+		go3270.Field{Row: 2, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
+	)
+
+	// One line per item, with the date it was first fetched
+	row := 3
+	rows := make(map[int]int) // screen row -> item
+	for i := s.page; i < len(items) && i < s.page+archiveRows; i++ {
+		color := go3270.White
+		if s.isRead(items[i].rssItem) {
+			color = go3270.Blue
+		}
+		line := fmt.Sprintf("%4d. %s %s", i+1, s.date(items[i].when()), items[i].Title)
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: max80(line, 80)[0], Color: color})
+		rows[row] = i
+		row++
+	}
+	msg := s.msg
+	switch {
+	case err != nil:
+		msg = fmt.Sprintf("Error reading archive: %v", err)
+	case len(items) == 0:
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: "(No items archived for this channel)", Color: go3270.Yellow})
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"errormsg": msg}
+
+	resp, err := go3270.HandleScreenAlt(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		0, 0,            // cursor coordinates
+		conn,    // network connection
+		devinfo, // device info for alternate screen size support
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		// Open the item under the cursor
+		if i, ok := rows[resp.Row]; ok {
+			return s.openArticle(items[i].Channel, items[i].rssItem)
+		}
+		return s.again(rssarchive)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssarchive)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF7:
+		s.page -= archiveRows
+		if s.page < 0 {
+			s.page = 0
+		}
+		return s.again(rssarchive)
+	case go3270.AIDPF8:
+		if s.page+archiveRows < len(items) {
+			s.page += archiveRows
+		} else {
+			s.msg = "Bottom of the archive"
+		}
+		return s.again(rssarchive)
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rssarchive)
+	}
+}
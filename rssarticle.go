@@ -0,0 +1,133 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"html"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+var tagRE = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips HTML markup from an item description.
+func plainText(s string) string {
+	s = tagRE.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return replaceUnhandledChar(strings.Join(strings.Fields(s), " "))
+}
+
+// articleKeys are the keys accepted on the rssarticle screen.
+var articleKeys = keyMap{Screen: "Article", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Return", Help: "Return to the headlines", Validate: true},
+	keyHelp,
+	keyReturn,
+	keyExit,
+	{AID: go3270.AIDPF10, Label: "Save", Help: "Save the article to read later"},
+}}
+
+func rssarticle(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := articleKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Article"
+	header := padCenter(title, 80)
+	channelTitle := fetchTitle(s.log, s.itemURL)
+
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: "Channel ", Color: go3270.Blue, Intense: true},
+		go3270.Field{Row: 1, Col: 8, Content: channelTitle, Color: go3270.Turquoise},
+		go3270.Field{Row: 2, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
+	)
+
+	// Title, then as much of the description as fits above the link
+	row := 3
+	for _, line := range wrap80(s.item.Title, 80) {
+		if row >= 19 {
+			break
+		}
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: line, Color: go3270.White, Intense: true})
+		row++
+	}
+	row++
+	if desc := plainText(s.item.Description); desc != "" {
+		for _, line := range wrap80(desc, 80) {
+			if row >= 20 { // leave space for the link
+				break
+			}
+			screen = append(screen, go3270.Field{Row: row, Col: 0, Content: line, Color: go3270.Green})
+			row++
+		}
+	}
+
+	link := strings.TrimSpace(s.item.Link)
+	if link != "" {
+		link = shorten(link)
+		screen = append(screen,
+			go3270.Field{Row: 20, Col: 0, Content: "Link ", Color: go3270.Blue, Intense: true},
+			go3270.Field{Row: 20, Col: 5, Content: padRight(link, 74), Color: go3270.Turquoise},
+		)
+	}
+
+	if t, ok := itemTime(s.item); ok {
+		screen = append(screen,
+			go3270.Field{Row: 21, Col: 0, Content: "Published ", Color: go3270.Blue, Intense: true},
+			go3270.Field{Row: 21, Col: 10, Content: s.dateTime(t) + ", " + age(t, ok) + " ago", Color: go3270.Turquoise},
+		)
+	}
+
+	screen = append(screen, go3270.Field{Row: 21, Col: 53, Name: "errormsg", Color: go3270.Yellow})
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"errormsg": s.msg}
+
+	resp, err := go3270.HandleScreenAlt(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		0, 0,            // cursor coordinates
+		conn,    // network connection
+		devinfo, // device info for alternate screen size support
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssarticle)
+	case go3270.AIDPF10:
+		// Save to read later
+		s.msg = s.saveItem(s.itemURL, s.item)
+		return s.again(rssarticle)
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// Back to the headlines
+		return s.back()
+	}
+}
@@ -0,0 +1,184 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// channelRows is the number of channels shown per page.
+const channelRows = 16
+
+// channelsKeys are the keys accepted on the rsschannels screen.
+var channelsKeys = keyMap{Screen: "Manage channels", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Process", Help: "Add the URL entered and carry out the line commands", Validate: true},
+	keyHelp,
+	{AID: go3270.AIDPF3, Label: "Return", Help: "Return to the channel selection"},
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show the previous page of channels"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show the next page of channels"},
+	keyExit,
+}}
+
+func rsschannels(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+	if !s.isAdmin(conn) {
+		return s.back()
+	}
+
+	keys := channelsKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Manage channels"
+	header := padCenter(title, 79)
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "Add URL:"},
+		go3270.Field{Row: 2, Col: 9, Name: "newURL", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 2, Col: 79, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 3, Col: 0, Content: "Name:"},
+		go3270.Field{Row: 3, Col: 9, Name: "newName", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 3, Col: 60, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 4, Col: 0, Content: "Line commands: D Delete  U Up  N Down  S Set as default  G Default of group", Color: go3270.Blue},
+	)
+
+	// Build list of channels, each with a line command field
+	list := channelList()
+	if s.page >= len(list) {
+		s.page = 0
+	}
+	row := 5
+	for i := s.page; i < len(list) && i < s.page+channelRows; i++ {
+		text := list[i].Title
+		if list[i].Group != "" {
+			text += "  [" + list[i].Group + "]"
+		}
+		screen = append(screen,
+			go3270.Field{Row: row, Col: 0, Name: fmt.Sprintf("cmd%d", i), Write: true, Highlighting: go3270.Underscore},
+			go3270.Field{Row: row, Col: 2, Content: padRight(fmt.Sprintf("%2d. %s", i, text), 77), Color: go3270.Yellow},
+		)
+		row++
+	}
+
+	//Footer
+	screen = append(screen,
+		go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true},
+	)
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"errormsg": s.msg}
+
+	resp, err := go3270.HandleScreen(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		2, 10,           // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		s.msg = manageChannels(s.log, list, resp.Values)
+		return s.again(rsschannels)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rsschannels)
+	case go3270.AIDPF7:
+		s.page -= channelRows
+		if s.page < 0 {
+			s.page = 0
+		}
+		return s.again(rsschannels)
+	case go3270.AIDPF8:
+		if s.page+channelRows < len(list) {
+			s.page += channelRows
+		}
+		return s.again(rsschannels)
+	case go3270.AIDPF3:
+		// Return to channel selection
+		return s.back()
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rsschannels)
+	}
+}
+
+// manageChannels carries out the add request and line commands entered on
+// the rsschannels screen. list is the channel list the screen was built
+// from, commands are matched to channels by url since earlier commands may
+// have moved them. The returned text is shown on the screen.
+func manageChannels(log *slog.Logger, list []channel, values map[string]string) string {
+	msgs := []string{}
+
+	if u := strings.TrimSpace(values["newURL"]); u != "" {
+		if err := checkURL(u); err != nil {
+			msgs = append(msgs, err.Error())
+		} else if _, err := fetchFeed(log, u); err != nil {
+			msgs = append(msgs, err.Error())
+		} else if err := addChannel(log, u, strings.TrimSpace(values["newName"])); err != nil {
+			msgs = append(msgs, err.Error())
+		} else {
+			msgs = append(msgs, "Channel added")
+		}
+	}
+
+	for i, c := range list {
+		cmd := strings.ToUpper(strings.TrimSpace(values[fmt.Sprintf("cmd%d", i)]))
+		if cmd == "" {
+			continue
+		}
+		cur := channelIndex(c.URL)
+		var err error
+		switch cmd {
+		case "D":
+			err = deleteChannel(cur)
+		case "U":
+			err = moveChannel(cur, cur-1)
+		case "N":
+			err = moveChannel(cur, cur+1)
+		case "S":
+			err = moveChannel(cur, 0)
+		case "G":
+			if c.Group == "" {
+				err = fmt.Errorf("%s is not in a group", c.Title)
+			} else {
+				err = moveChannel(cur, groupStart(c.Group))
+			}
+		default:
+			err = fmt.Errorf("unknown command %s", cmd)
+		}
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return strings.Join(msgs, ", ")
+}
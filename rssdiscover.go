@@ -0,0 +1,263 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// feedLink is a feed announced by a web page.
+type feedLink struct {
+	Title string
+	URL   string
+}
+
+var (
+	linkTagRE  = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	linkAttrRE = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+)
+
+// discoverFeeds returns the feeds found at pageURL. If pageURL is a feed
+// itself it is returned as the only entry, whatever its content type, if it
+// is an HTML page the <link rel="alternate"> tags for RSS and Atom feeds are
+// returned.
+func discoverFeeds(pageURL string) ([]feedLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	// Many servers send feeds as text/html, so whatever the content type
+	// says, the page is a feed if it decodes as one
+	_, ferr := decodeFeed(bytes.NewReader(body))
+	if ferr == nil {
+		return []feedLink{{URL: pageURL}}, nil
+	}
+	ctype := resp.Header.Get("Content-Type")
+	if ctype == "" {
+		ctype = http.DetectContentType(body)
+	}
+	if !strings.Contains(ctype, "html") {
+		return nil, ferr
+	}
+
+	// Relative links are relative to the page reached after any redirects
+	base := resp.Request.URL
+	out := []feedLink{}
+	for _, tag := range linkTagRE.FindAllString(string(body), -1) {
+		attrs := map[string]string{}
+		for _, m := range linkAttrRE.FindAllStringSubmatch(tag, -1) {
+			v := strings.Trim(m[2], `"'`)
+			attrs[strings.ToLower(m[1])] = html.UnescapeString(v)
+		}
+		if !hasToken(attrs["rel"], "alternate") {
+			continue
+		}
+		// The type may carry parameters, e.g. "; charset=utf-8"
+		mtype, _, err := mime.ParseMediaType(attrs["type"])
+		if err != nil {
+			continue
+		}
+		switch mtype {
+		case "application/rss+xml", "application/atom+xml":
+		default:
+			continue
+		}
+		href, err := base.Parse(attrs["href"])
+		if err != nil || attrs["href"] == "" {
+			continue
+		}
+		out = append(out, feedLink{
+			Title: replaceUnhandledChar(strings.TrimSpace(attrs["title"])),
+			URL:   href.String(),
+		})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no feeds found on %s", pageURL)
+	}
+	return out, nil
+}
+
+// hasToken reports whether the space separated list s contains tok.
+func hasToken(s, tok string) bool {
+	for _, f := range strings.Fields(strings.ToLower(s)) {
+		if f == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL validates a url typed by the user.
+func checkURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL must start with http:// or https://")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL has no host name")
+	}
+	return nil
+}
+
+// resolveFeed validates newURL and probes it. The result is the feed at
+// newURL, or the feeds announced by the web page at newURL.
+func resolveFeed(log *slog.Logger, newURL string) ([]feedLink, error) {
+	if err := checkURL(newURL); err != nil {
+		return nil, err
+	}
+	feeds, err := discoverFeeds(newURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(feeds) == 1 && feeds[0].URL != newURL {
+		if _, err := fetchFeed(log, feeds[0].URL); err != nil {
+			return nil, err
+		}
+	}
+	return feeds, nil
+}
+
+// selectFeed switches to a single feed directly, several feeds are offered
+// on the rssdiscover screen.
+func (s *session) selectFeed(feeds []feedLink) (go3270.Tx, any, error) {
+	if len(feeds) == 1 {
+		s.setChannel(feeds[0].URL)
+		return s.show(rssfeed)
+	}
+	// Two rows per feed, so only the first eight fit on the screen
+	if len(feeds) > 8 {
+		feeds = feeds[:8]
+	}
+	s.feeds = feeds
+	return s.show(rssdiscover)
+}
+
+// discoverKeys are the keys accepted on the rssdiscover screen.
+var discoverKeys = keyMap{Screen: "Feeds found", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Select", Help: "Switch to the feed with the number entered", Validate: true},
+	keyHelp,
+	{AID: go3270.AIDPF3, Label: "Return", Help: "Return to the previous screen without changing channel"},
+	keyExit,
+}}
+
+func rssdiscover(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := discoverKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Feeds found"
+	header := padCenter(title, 79)
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "The page lists more than one feed."},
+		go3270.Field{Row: 3, Col: 0, Content: "Select one of the below feeds:"},
+		go3270.Field{Row: 3, Col: 31, Write: true, Name: "choice", Content: "0", Color: go3270.Turquoise, NumericOnly: true},
+		go3270.Field{Row: 3, Col: 34, Autoskip: true}, // field "stop" character
+	)
+
+	// Build list of feeds, title and url on separate lines
+	row := 4
+	for i, f := range s.feeds {
+		if row >= 20 {
+			break
+		}
+		t := f.Title
+		if t == "" {
+			t = "No Title found"
+		}
+		screen = append(screen,
+			go3270.Field{Row: row, Col: 0, Content: padRight(fmt.Sprintf("%2d. %s", i, t), 80), Color: go3270.Yellow},
+			go3270.Field{Row: row + 1, Col: 4, Content: padRight(f.URL, 76), Color: go3270.Turquoise},
+		)
+		row += 2
+	}
+
+	//Footer
+	screen = append(screen,
+		go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true},
+	)
+	screen = append(screen, keys.footer()...)
+
+	resp, err := go3270.HandleScreen(
+		screen,                    // the screen to display
+		choiceRules(len(s.feeds)), // rules to enforce
+		nil,                       // pre-populated values in fields
+		keys.pfkeys(),             // keys we accept -- validating
+		keys.exitkeys(),           // keys we accept -- non-validating
+		"errormsg",                // name of field to put error messages in
+		3, 32,                     // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		if i := parseChoice(resp.Values["choice"]); i >= 0 {
+			s.setChannel(s.feeds[i].URL)
+			return s.show(rssfeed)
+		}
+		return s.again(rssdiscover)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssdiscover)
+	case go3270.AIDPF3:
+		// Return without changing channel
+		return s.back()
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rssdiscover)
+	}
+}
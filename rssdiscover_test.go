@@ -0,0 +1,113 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Test</title>
+<item><title>One</title><link>https://example.com/1</link></item>
+</channel></rss>`
+
+func TestDiscoverFeeds(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctype    string
+		body     string
+		redirect string   // where /page redirects to, if anywhere
+		want     []string // urls found, relative to the server
+	}{
+		{
+			name:  "feed as rss",
+			ctype: "application/rss+xml",
+			body:  testFeed,
+			want:  []string{"/page"},
+		},
+		{
+			name:  "feed as html",
+			ctype: "text/html; charset=utf-8",
+			body:  testFeed,
+			want:  []string{"/page"},
+		},
+		{
+			name:  "link type with charset",
+			ctype: "text/html",
+			body: `<!DOCTYPE html><html><head>
+<link rel="alternate" type="application/rss+xml; charset=utf-8" title="News" href="/news.xml">
+</head><body></body></html>`,
+			want: []string{"/news.xml"},
+		},
+		{
+			name:  "rss and atom links",
+			ctype: "text/html",
+			body: `<html><head>
+<link rel="stylesheet" type="text/css" href="/style.css">
+<link rel="alternate" type="application/rss+xml" href="/rss">
+<link rel="alternate" type="application/atom+xml" href="https://feeds.example.com/atom">
+</head></html>`,
+			want: []string{"/rss", "https://feeds.example.com/atom"},
+		},
+		{
+			name:     "relative link after redirect",
+			ctype:    "text/html",
+			body:     `<html><head><link rel="alternate" type="application/rss+xml" href="feed.xml"></head></html>`,
+			redirect: "/news/",
+			want:     []string{"/news/feed.xml"},
+		},
+		{
+			name:  "no feeds",
+			ctype: "text/html",
+			body:  `<html><head><title>Nothing</title></head></html>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.redirect != "" && r.URL.Path == "/page" {
+					http.Redirect(w, r, tt.redirect, http.StatusFound)
+					return
+				}
+				w.Header().Set("Content-Type", tt.ctype)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			feeds, err := discoverFeeds(srv.URL + "/page")
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("got %v, want an error", feeds)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, f := range feeds {
+				got = append(got, f.URL)
+			}
+			want := []string{}
+			for _, u := range tt.want {
+				if u[0] == '/' {
+					u = srv.URL + u
+				}
+				want = append(want, u)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
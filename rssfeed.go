@@ -18,97 +18,146 @@ import (
 	"github.com/racingmars/go3270"
 )
 
-func rssfeed(conn net.Conn, devinfo go3270.DevInfo, rssFeedURL any) (
+// headlineKeys are the keys the rssfeed and rssfeedlinks screens share.
+var headlineKeys = []key{
+	{AID: go3270.AIDEnter, Label: "Refresh", Help: "Read the headline under the cursor, or refresh the headlines", Validate: true},
+	keyHelp,
+	keyChannels,
+	keyRecent,
+	keyExit,
+	keyRiver,
+	keySave,
+	keySearch,
+}
+
+// feedKeys are the keys accepted on the rssfeed screen.
+var feedKeys = keyMap{Screen: "Headlines", Keys: headlineKeys}.with(
+	key{AID: go3270.AIDPF2, Label: "Links", Help: "Show the headlines with short links to the articles"},
+)
+
+// headlineKeysFor returns km with the keys of the headline screens that
+// depend on the session and the server.
+func (s *session) headlineKeysFor(km keyMap) keyMap {
+	if archive != nil {
+		km = km.with(keyArchive)
+	}
+	if s.userID != "" {
+		km = km.with(keyFilter)
+	}
+	if len(s.history) > 0 {
+		km = km.with(keyReturn)
+	}
+	return km
+}
+
+func rssfeed(conn net.Conn, devinfo go3270.DevInfo, data any) (
 	go3270.Tx, any, error) {
 
-	currentURL := rssFeedURL.(string)
+	s := data.(*session)
 
-	// Accept Enter; PF3 exit and PF4 new url.
-	pfkeys := []go3270.AID{go3270.AIDEnter, go3270.AIDPF2, go3270.AIDPF3, go3270.AIDPF4}
-	exitkeys := []go3270.AID{go3270.AIDPF9}
+	keys := s.headlineKeysFor(feedKeys)
 
-	headlines, err := fetchHeadlines(currentURL, maxHeadlines)
+	items, hidden, err := s.feedItems()
+	lines := headlines(items)
 	if err != nil {
-		headlines = []string{fmt.Sprintf("Error fetching feed: %v", err)}
+		lines = []string{fmt.Sprintf("Error fetching feed: %v", err)}
 	}
 
 	// Make a local copy of the screen definition that we can append lines to.
 	screen := make(go3270.Screen, len(layout))
 	copy(screen, layout)
 
-	now := time.Now().UTC().Format("15:04 UTC")
+	now := s.clock(time.Now())
 	title := "RSS Feed"
 	header := padCenter(title, 80)
-	channelTitle := fetchTitle(currentURL)
-	if err != nil {
-		headlines = []string{fmt.Sprintf("Error fetching Channel Title: %v", err)}
-	}
+	channelTitle := fetchTitle(s.log, s.url)
 
 	screen = append(screen,
 		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
 		go3270.Field{Row: 1, Col: 0, Content: "Channel ", Color: go3270.Blue, Intense: true},
 		go3270.Field{Row: 1, Col: 8, Content: channelTitle, Color: go3270.Turquoise},
-		go3270.Field{Row: 1, Col: 62, Content: "Updated ", Color: go3270.Blue},
-		go3270.Field{Row: 1, Col: 70, Content: now, Color: go3270.Turquoise},
+		go3270.Field{Row: 1, Col: 58, Content: "Updated ", Color: go3270.Blue},
+		go3270.Field{Row: 1, Col: 66, Content: padRight(now, 13), Color: go3270.Turquoise},
 		go3270.Field{Row: 2, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
 	)
+	screen = append(screen, hiddenNote(hidden)...)
 
-	row := 3
-	for i, h := range headlines {
-		for _, line := range wrap80(fmt.Sprintf("%2d. %s", i+1, strings.TrimSpace(h)), 80) {
-			if row >= 22 { // leave space for footer/input
-				break
-			}
-			screen = append(screen, go3270.Field{Row: row, Col: 0, Content: line, Color: go3270.White})
-			row++
-		}
-		if row >= 22 {
-			break
-		}
+	read := make(map[int]bool)
+	for i, it := range items {
+		read[i] = s.isRead(it)
 	}
+	fields, rows := headlineRows(lines, read)
+	screen = append(screen, fields...)
 
-	screen = append(screen,
-		go3270.Field{Row: 22, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
-		go3270.Field{Row: 23, Col: 0, Content: "Enter", Color: go3270.Turquoise, Intense: true},
-		go3270.Field{Row: 23, Col: 6, Content: "Refresh", Color: go3270.Blue, Intense: true},
-		go3270.Field{Row: 23, Col: 22, Content: "F2", Color: go3270.Turquoise, Intense: true},
-		go3270.Field{Row: 23, Col: 25, Content: "Headline links", Color: go3270.Blue, Intense: true},
-		go3270.Field{Row: 23, Col: 45, Content: "F4", Color: go3270.Turquoise, Intense: true},
-		go3270.Field{Row: 23, Col: 48, Content: "Change channel", Color: go3270.Blue, Intense: true},
-		go3270.Field{Row: 23, Col: 69, Content: "F9", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 72, Content: "Exit", Color: go3270.Blue},
-	)
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Yellow})
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"errormsg": s.msg}
 
 	resp, err := go3270.HandleScreenAlt(
-		screen,     // the screen to display
-		nil,        // (no) rules to enforce
-		nil,        // pre-populated values in fields
-		pfkeys,     // keys we accept -- validating
-		exitkeys,   // keys we accept -- non-validating
-		"errormsg", // name of field to put error messages in
-		0, 0,       // cursor coordinates
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		0, 0,            // cursor coordinates
 		conn,    // network connection
 		devinfo, // device info for alternate screen size support
 	)
 	if err != nil {
 		return nil, nil, err
 	}
+	s.msg = ""
 
 	switch resp.AID {
 	case go3270.AIDEnter:
-		// Re-run current transaction, echoing back input
-		return rssfeed, currentURL, err
+		// Open the headline under the cursor, or refresh
+		if i, ok := rows[resp.Row]; ok && i < len(items) {
+			return s.openArticle(s.url, items[i])
+		}
+		forgetFeeds(s.url)
+		return s.again(rssfeed)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssfeed)
 	case go3270.AIDPF2:
-		// Go to default screen size transaction
-		return rssfeedlinks, currentURL, nil
+		// Headlines with links
+		return s.show(rssfeedlinks)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
 	case go3270.AIDPF4:
-		// Go to default screen size transaction
-		return rsstitles, currentURL, nil
+		// Select another channel
+		return s.show(rsstitles)
+	case go3270.AIDPF5:
+		// Channels viewed before
+		return s.show(rssrecent)
+	case go3270.AIDPF6:
+		// Older items of the channel
+		return s.show(rssarchive)
+	case go3270.AIDPF13:
+		// All channels together
+		return s.show(rssriver)
+	case go3270.AIDPF10:
+		// Save the headline under the cursor
+		if i, ok := rows[resp.Row]; ok && i < len(items) {
+			s.msg = s.saveItem(s.url, items[i])
+		} else {
+			s.msg = "Move the cursor to a headline to save it"
+		}
+		return s.again(rssfeed)
+	case go3270.AIDPF11:
+		// Search all channels
+		return s.show(rsssearch)
+	case go3270.AIDPF12:
+		// Filters of the channel
+		return s.show(rssfilter)
 	case go3270.AIDPF9:
 		// Exit
-		return nil, nil, nil
+		return s.exit()
 	default:
 		// re-run current transaction
-		return rssfeed, currentURL, err
+		return s.again(rssfeed)
 	}
 }
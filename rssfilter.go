@@ -0,0 +1,166 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// filterKeys are the keys accepted on the rssfilter screen.
+var filterKeys = keyMap{Screen: "Filters", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Save", Help: "Save the filters of the channel in the profile", Validate: true},
+	keyHelp,
+	keyReturn,
+	keyExit,
+}}
+
+// keyFilter is offered to signed on users on the headline screens.
+var keyFilter = key{AID: go3270.AIDPF12, Label: "Filter", Help: "Hide or only show headlines with some words"}
+
+// filterRows is the number of include and of exclude patterns that can be
+// entered.
+const filterRows = 5
+
+func rssfilter(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+	if s.userID == "" {
+		return s.back()
+	}
+
+	keys := filterKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Filters"
+	header := padCenter(title, 79)
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "Channel: " + channelName(s.url), Color: go3270.Turquoise},
+		go3270.Field{Row: 3, Col: 0, Content: "A keyword matches anywhere in the title or text, ignoring case. Put a", Color: go3270.Blue},
+		go3270.Field{Row: 4, Col: 0, Content: "regular expression between slashes, e.g. /(?i)^sport/", Color: go3270.Blue},
+		go3270.Field{Row: 6, Col: 0, Content: "Only show headlines with", Intense: true},
+		go3270.Field{Row: 6, Col: 40, Content: "Hide headlines with", Intense: true},
+	)
+
+	// One field per pattern, include on the left and exclude on the right
+	f := s.prof.Filters[s.url]
+	fieldValues := map[string]string{"errormsg": s.msg}
+	for i := 0; i < filterRows; i++ {
+		inc, exc := fmt.Sprintf("inc%d", i), fmt.Sprintf("exc%d", i)
+		if i < len(f.Include) {
+			fieldValues[inc] = f.Include[i]
+		}
+		if i < len(f.Exclude) {
+			fieldValues[exc] = f.Exclude[i]
+		}
+		screen = append(screen,
+			go3270.Field{Row: 7 + i, Col: 0, Name: inc, Write: true, Highlighting: go3270.Underscore},
+			go3270.Field{Row: 7 + i, Col: 38, Autoskip: true}, // field "stop" character
+			go3270.Field{Row: 7 + i, Col: 40, Name: exc, Write: true, Highlighting: go3270.Underscore},
+			go3270.Field{Row: 7 + i, Col: 78, Autoskip: true}, // field "stop" character
+		)
+	}
+
+	// The filters of the channel apply to everyone
+	cf := channelFilter(s.url)
+	row := 8 + filterRows
+	if len(cf.Include) > 0 || len(cf.Exclude) > 0 {
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: "Set for the channel by the administrator:", Color: go3270.Blue})
+		row++
+		for _, p := range cf.Include {
+			if row < 21 {
+				screen = append(screen, go3270.Field{Row: row, Col: 0, Content: max80("+ "+p, 79)[0], Color: go3270.Yellow})
+				row++
+			}
+		}
+		for _, p := range cf.Exclude {
+			if row < 21 {
+				screen = append(screen, go3270.Field{Row: row, Col: 0, Content: max80("- "+p, 79)[0], Color: go3270.Yellow})
+				row++
+			}
+		}
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	resp, err := go3270.HandleScreen(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		7, 1,            // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		var nf filter
+		for i := 0; i < filterRows; i++ {
+			if p := strings.TrimSpace(resp.Values[fmt.Sprintf("inc%d", i)]); p != "" {
+				nf.Include = append(nf.Include, p)
+			}
+			if p := strings.TrimSpace(resp.Values[fmt.Sprintf("exc%d", i)]); p != "" {
+				nf.Exclude = append(nf.Exclude, p)
+			}
+		}
+		if err := nf.check(); err != nil {
+			s.msg = max80(err.Error(), 79)[0]
+			return s.again(rssfilter)
+		}
+		// A new map, the old one is shared with the users list
+		filters := make(map[string]filter)
+		for url, f := range s.prof.Filters {
+			filters[url] = f
+		}
+		if len(nf.Include) == 0 && len(nf.Exclude) == 0 {
+			delete(filters, s.url)
+		} else {
+			filters[s.url] = nf
+		}
+		s.prof.Filters = filters
+		if err := saveProfile(s.userID, s.prof); err != nil {
+			s.msg = err.Error()
+		} else {
+			s.msg = "Filters saved"
+		}
+		return s.again(rssfilter)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssfilter)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rssfilter)
+	}
+}
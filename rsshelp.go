@@ -0,0 +1,75 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+func rsshelp(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Help - " + s.helpKeys.Screen
+	header := padCenter(title, 80)
+
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
+	)
+
+	row := 3
+	names, keys := s.helpKeys.sorted()
+	for i, k := range keys {
+		if row >= 21 {
+			break
+		}
+		screen = append(screen,
+			go3270.Field{Row: row, Col: 2, Content: names[i], Color: go3270.Turquoise, Intense: true},
+			go3270.Field{Row: row, Col: 9, Content: padRight(k.Help, 70), Color: go3270.Green},
+		)
+		row++
+	}
+
+	screen = append(screen,
+		go3270.Field{Row: 22, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 23, Col: 0, Content: "Press any key to return", Color: go3270.Blue, Intense: true},
+	)
+
+	// Any key returns
+	anykey := []go3270.AID{}
+	for _, o := range keyOrder {
+		anykey = append(anykey, o.AID)
+	}
+
+	_, err := go3270.HandleScreenAlt(
+		screen,     // the screen to display
+		nil,        // (no) rules to enforce
+		nil,        // pre-populated values in fields
+		nil,        // keys we accept -- validating
+		anykey,     // keys we accept -- non-validating
+		"errormsg", // name of field to put error messages in
+		0, 0,       // cursor coordinates
+		conn,    // network connection
+		devinfo, // device info for alternate screen size support
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.again(s.helpBack)
+}
@@ -0,0 +1,228 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/racingmars/go3270"
+)
+
+// profileKeys are the keys accepted on the rssprofile screen.
+var profileKeys = keyMap{Screen: "Profile", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Save", Help: "Save the profile", Validate: true},
+	keyHelp,
+	keyReturn,
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Keep the changes and show the previous page of channels", Validate: true},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Keep the changes and show the next page of channels", Validate: true},
+	keyExit,
+}}
+
+// keyProfile is offered to signed on users.
+var keyProfile = key{AID: go3270.AIDPF10, Label: "Profile", Help: "Change the default channel, subscribed channels and preferences"}
+
+// profileRows is the number of channels shown per page.
+const profileRows = 12
+
+func rssprofile(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+	if s.userID == "" {
+		return s.back()
+	}
+
+	keys := profileKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Profile for " + s.userID
+	header := padCenter(title, 79)
+
+	list := channelList()
+	if s.page >= len(list) {
+		s.page = 0
+	}
+	def := ""
+	if i := channelIndex(s.prof.DefaultChannel); i >= 0 {
+		def = fmt.Sprint(i)
+	}
+	links := "N"
+	if s.prof.Links {
+		links = "Y"
+	}
+	newest := ""
+	switch s.prof.Sort {
+	case "date":
+		newest = "Y"
+	case "feed":
+		newest = "N"
+	}
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "Default channel . . . ."},
+		go3270.Field{Row: 2, Col: 24, Name: "default", Write: true, NumericOnly: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 2, Col: 27, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 3, Col: 0, Content: "Start on links (Y/N) ."},
+		go3270.Field{Row: 3, Col: 24, Name: "links", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 3, Col: 26, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 4, Col: 0, Content: "Newest first (Y/N) . ."},
+		go3270.Field{Row: 4, Col: 24, Name: "newest", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 4, Col: 26, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 4, Col: 28, Content: "blank for the server default", Color: go3270.Blue},
+		go3270.Field{Row: 5, Col: 0, Content: "Time zone  . . . . . ."},
+		go3270.Field{Row: 5, Col: 24, Name: "zone", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 5, Col: 57, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 5, Col: 59, Content: "e.g. Europe/Copenhagen", Color: go3270.Blue},
+		go3270.Field{Row: 6, Col: 0, Content: "Date format (ISO/EU/US)"},
+		go3270.Field{Row: 6, Col: 24, Name: "datefmt", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 6, Col: 28, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 6, Col: 30, Content: fmt.Sprintf("now %s", s.dateTime(time.Now())), Color: go3270.Blue},
+		go3270.Field{Row: 8, Col: 0, Content: "Subscribed channels, S to subscribe. With none subscribed all are shown.", Color: go3270.Blue},
+	)
+
+	// Build list of channels, each with a subscribe field
+	subscribed := make(map[string]bool)
+	for _, url := range s.prof.Channels {
+		subscribed[url] = true
+	}
+	fieldValues := map[string]string{"default": def, "links": links, "newest": newest, "zone": s.prof.Zone, "datefmt": s.prof.DateFormat, "errormsg": s.msg}
+	row := 9
+	for i := s.page; i < len(list) && i < s.page+profileRows; i++ {
+		name := fmt.Sprintf("sub%d", i)
+		if subscribed[list[i].URL] {
+			fieldValues[name] = "S"
+		}
+		screen = append(screen,
+			go3270.Field{Row: row, Col: 0, Name: name, Write: true, Highlighting: go3270.Underscore},
+			go3270.Field{Row: row, Col: 2, Content: padRight(fmt.Sprintf("%2d. %s", i, list[i].Title), 77), Color: go3270.Yellow},
+		)
+		row++
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	rules := choiceRules(len(list))
+	rules["default"] = rules["choice"]
+	delete(rules, "choice")
+	rules["links"] = go3270.FieldRules{
+		Validator: func(input string) bool {
+			switch strings.ToUpper(strings.TrimSpace(input)) {
+			case "Y", "N", "":
+				return true
+			}
+			return false
+		},
+		ErrorText: "Please enter Y or N",
+	}
+	rules["newest"] = rules["links"]
+	rules["zone"] = go3270.FieldRules{
+		Validator: func(input string) bool {
+			_, err := time.LoadLocation(strings.TrimSpace(input))
+			return err == nil
+		},
+		ErrorText: "Unknown time zone, e.g. Europe/Copenhagen or UTC",
+	}
+	rules["datefmt"] = go3270.FieldRules{
+		Validator: func(input string) bool {
+			input = strings.ToUpper(strings.TrimSpace(input))
+			_, ok := dateStyles[input]
+			return ok || input == ""
+		},
+		ErrorText: "Please enter ISO, EU or US",
+	}
+
+	resp, err := go3270.HandleScreen(
+		screen,          // the screen to display
+		rules,           // rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		2, 25,           // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter, go3270.AIDPF7, go3270.AIDPF8:
+		// Take the values from the screen, subscriptions only for the
+		// channels on this page
+		if i := parseChoice(resp.Values["default"]); i >= 0 {
+			s.prof.DefaultChannel = list[i].URL
+		} else {
+			s.prof.DefaultChannel = ""
+		}
+		s.prof.Links = strings.ToUpper(strings.TrimSpace(resp.Values["links"])) == "Y"
+		s.prof.Zone = strings.TrimSpace(resp.Values["zone"])
+		s.prof.DateFormat = strings.ToUpper(strings.TrimSpace(resp.Values["datefmt"]))
+		switch strings.ToUpper(strings.TrimSpace(resp.Values["newest"])) {
+		case "Y":
+			s.prof.Sort = "date"
+		case "N":
+			s.prof.Sort = "feed"
+		default:
+			s.prof.Sort = ""
+		}
+		for i := s.page; i < len(list) && i < s.page+profileRows; i++ {
+			subscribed[list[i].URL] = strings.ToUpper(strings.TrimSpace(resp.Values[fmt.Sprintf("sub%d", i)])) == "S"
+		}
+		s.prof.Channels = nil
+		for _, c := range list {
+			if subscribed[c.URL] {
+				s.prof.Channels = append(s.prof.Channels, c.URL)
+			}
+		}
+
+		switch resp.AID {
+		case go3270.AIDPF7:
+			s.page -= profileRows
+			if s.page < 0 {
+				s.page = 0
+			}
+		case go3270.AIDPF8:
+			if s.page+profileRows < len(list) {
+				s.page += profileRows
+			}
+		default:
+			if err := saveProfile(s.userID, s.prof); err != nil {
+				s.msg = err.Error()
+			} else {
+				s.msg = "Profile saved"
+			}
+		}
+		return s.again(rssprofile)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssprofile)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rssprofile)
+	}
+}
@@ -0,0 +1,106 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// recentKeys are the keys accepted on the rssrecent screen.
+var recentKeys = keyMap{Screen: "Recent channels", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Select", Help: "Switch to the channel under the cursor or the channel number", Validate: true},
+	keyHelp,
+	keyReturn,
+	keyExit,
+}}
+
+func rssrecent(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := recentKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Recent channels"
+	header := padCenter(title, 79)
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "Channel now: " + channelName(s.url), Color: go3270.Turquoise},
+		go3270.Field{Row: 3, Col: 0, Content: "Select one of the channels viewed before:"},
+		go3270.Field{Row: 3, Col: 42, Write: true, Name: "choice", Color: go3270.Turquoise, NumericOnly: true},
+		go3270.Field{Row: 3, Col: 45, Autoskip: true}, // field "stop" character
+	)
+
+	// Build list of recent channels, latest first
+	row := 5
+	rows := make(map[int]int) // screen row -> recent channel
+	for i, url := range s.recent {
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: padRight(fmt.Sprintf("%2d. %s", i, channelName(url)), 80), Color: go3270.Yellow})
+		rows[row] = i
+		row++
+	}
+	if len(s.recent) == 0 {
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: "(No channels viewed before)", Color: go3270.Yellow})
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	resp, err := go3270.HandleScreen(
+		screen,                     // the screen to display
+		choiceRules(len(s.recent)), // rules to enforce
+		nil,                        // pre-populated values in fields
+		keys.pfkeys(),              // keys we accept -- validating
+		keys.exitkeys(),            // keys we accept -- non-validating
+		"errormsg",                 // name of field to put error messages in
+		3, 43,                      // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		i, ok := rows[resp.Row]
+		if !ok {
+			i = parseChoice(resp.Values["choice"])
+		}
+		if i < 0 || i >= len(s.recent) {
+			return s.back()
+		}
+		s.setChannel(s.recent[i])
+		return s.show(rssfeed)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssrecent)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rssrecent)
+	}
+}
@@ -0,0 +1,228 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/racingmars/go3270"
+)
+
+const (
+	riverRows     = 18  // items shown per page
+	maxRiver      = 300 // items kept in the river
+	riverFetchers = 8   // channels fetched at the same time
+)
+
+// keyRiver is offered on the screens that lead to a single channel. F7
+// and F8 page on the paged screens, so the river is on F13, Shift+F1 on
+// most emulators.
+var keyRiver = key{AID: go3270.AIDPF13, Label: "River", Help: "Show the latest items of all channels, or of the group, together"}
+
+// riverKeys are the keys accepted on the rssriver screen.
+var riverKeys = keyMap{Screen: "River of news", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Refresh", Help: "Read the item under the cursor, or refresh the items", Validate: true},
+	keyHelp,
+	keyReturn,
+	keyChannels,
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show newer items"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show older items"},
+	keyExit,
+	keySave,
+}}
+
+// riverItems returns the items of the channels at urls, after the filters
+// of each channel and the profile, newest first. Items without a date come
+// last. It also returns the number of items the filters hid.
+func (s *session) riverItems(urls []string) ([]archivedItem, int) {
+	var (
+		out    []archivedItem
+		hidden int
+	)
+	now := time.Now().UTC()
+	fetchChannels(s.log, urls, func(url string, items []rssItem, err error) {
+		if err != nil {
+			// The failed fetch was logged
+			return
+		}
+		items, h := applyFilters(items, channelFilter(url), s.prof.Filters[url])
+		hidden += h
+		for _, it := range items {
+			out = append(out, newArchivedItem(it, url, now))
+		}
+	})
+
+	sortArchived(out)
+	if len(out) > maxRiver {
+		out = out[:maxRiver]
+	}
+	return out, hidden
+}
+
+// fetchChannels fetches the items of the channels at urls, riverFetchers
+// at a time, and calls got with the items of each. The calls to got are
+// one at a time.
+func fetchChannels(log *slog.Logger, urls []string, got func(url string, items []rssItem, err error)) {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, riverFetchers)
+	for _, url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			items, err := fetchItems(log, url)
+			<-sem
+			mu.Lock()
+			defer mu.Unlock()
+			got(url, items, err)
+		}(url)
+	}
+	wg.Wait()
+}
+
+// channelTag returns the short name of the channel at url shown in front of
+// its items in the river.
+func channelTag(url string) string {
+	return fmt.Sprintf("%-8.8s", strings.TrimSpace(channelName(url)))
+}
+
+func rssriver(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := riverKeys
+
+	list := s.channelList()
+	urls := make([]string, 0, len(list))
+	for _, c := range list {
+		if s.group == "" || c.Group == s.group {
+			urls = append(urls, c.URL)
+		}
+	}
+	items, hidden := s.riverItems(urls)
+	if s.page >= len(items) {
+		s.page = 0
+	}
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	now := s.clock(time.Now())
+	title := "River of news"
+	if s.group != "" {
+		title += " - " + s.group
+	}
+	header := padCenter(title, 80)
+
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: "Channels ", Color: go3270.Blue, Intense: true},
+		go3270.Field{Row: 1, Col: 9, Content: fmt.Sprintf("%d, %d items", len(urls), len(items)), Color: go3270.Turquoise},
+		go3270.Field{Row: 1, Col: 58, Content: "Updated ", Color: go3270.Blue},
+		go3270.Field{Row: 1, Col: 66, Content: padRight(now, 13), Color: go3270.Turquoise},
+		go3270.Field{Row: 2, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
+	)
+	screen = append(screen, hiddenNote(hidden)...)
+
+	// One line per item, the channel tag, the age and the title
+	row := 3
+	rows := make(map[int]int) // screen row -> item
+	for i := s.page; i < len(items) && i < s.page+riverRows; i++ {
+		color := go3270.White
+		if s.isRead(items[i].rssItem) {
+			color = go3270.Blue
+		}
+		screen = append(screen,
+			go3270.Field{Row: row, Col: 0, Content: channelTag(items[i].Channel), Color: go3270.Turquoise},
+			go3270.Field{Row: row, Col: 9, Content: max80(itemAge(items[i].rssItem)+items[i].Title, 70)[0], Color: color},
+		)
+		rows[row] = i
+		row++
+	}
+	if len(items) == 0 {
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: "(No headlines found)", Color: go3270.Yellow})
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"errormsg": s.msg}
+
+	resp, err := go3270.HandleScreenAlt(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		0, 0,            // cursor coordinates
+		conn,    // network connection
+		devinfo, // device info for alternate screen size support
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		// Open the item under the cursor, or refresh
+		if i, ok := rows[resp.Row]; ok {
+			return s.openArticle(items[i].Channel, items[i].rssItem)
+		}
+		forgetFeeds(urls...)
+		return s.again(rssriver)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rssriver)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF4:
+		// Select another channel
+		return s.show(rsstitles)
+	case go3270.AIDPF7:
+		s.page -= riverRows
+		if s.page < 0 {
+			s.page = 0
+		}
+		return s.again(rssriver)
+	case go3270.AIDPF8:
+		if s.page+riverRows < len(items) {
+			s.page += riverRows
+		}
+		return s.again(rssriver)
+	case go3270.AIDPF10:
+		// Save the item under the cursor
+		if i, ok := rows[resp.Row]; ok {
+			s.msg = s.saveItem(items[i].Channel, items[i].rssItem)
+		} else {
+			s.msg = "Move the cursor to an item to save it"
+		}
+		return s.again(rssriver)
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rssriver)
+	}
+}
@@ -0,0 +1,175 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// savedRows is the number of saved items shown per page.
+const savedRows = 16
+
+// keySave saves the item under the cursor, or the article shown.
+var keySave = key{AID: go3270.AIDPF10, Label: "Save", Help: "Save the headline under the cursor to read later"}
+
+// keySaved is offered on the channel screen.
+var keySaved = key{AID: go3270.AIDPF12, Label: "Saved", Help: "Show the items saved to read later"}
+
+// savedKeys are the keys accepted on the rsssaved screen.
+var savedKeys = keyMap{Screen: "Saved items", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Process", Help: "Carry out the line commands, or read the item under the cursor", Validate: true},
+	keyHelp,
+	keyReturn,
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show the previous page of saved items"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show the next page of saved items"},
+	keyExit,
+}}
+
+// keyExport is offered when the saved items can be written to a file.
+var keyExport = key{AID: go3270.AIDPF6, Label: "Export", Help: "Write the saved items to a text file on the server"}
+
+func rsssaved(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := savedKeys
+	if exportDir != "" && s.owner() != "" {
+		keys = keys.with(keyExport)
+	}
+
+	saved := s.prof.Saved
+	if s.page >= len(saved) {
+		s.page = 0
+	}
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Saved items"
+	header := padCenter(title, 79)
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "Line commands: D Delete", Color: go3270.Blue},
+	)
+
+	// One line per item, with a line command field and the short link
+	row := 4
+	rows := make(map[int]int) // screen row -> saved item
+	for i := s.page; i < len(saved) && i < s.page+savedRows; i++ {
+		// Shortened when saved, the full link cut to fit for items saved
+		// before short links were kept
+		link := saved[i].Short
+		if link == "" {
+			link = strings.TrimSpace(saved[i].Link)
+		}
+		color := go3270.White
+		if s.isRead(saved[i].rssItem) {
+			color = go3270.Blue
+		}
+		line := fmt.Sprintf("%3d. %s %s", i+1, padRight(saved[i].Title, 40), link)
+		screen = append(screen,
+			go3270.Field{Row: row, Col: 0, Name: fmt.Sprintf("cmd%d", i), Write: true, Highlighting: go3270.Underscore},
+			go3270.Field{Row: row, Col: 2, Content: padRight(line, 77), Color: color},
+		)
+		rows[row] = i
+		row++
+	}
+	if len(saved) == 0 {
+		screen = append(screen, go3270.Field{Row: row, Col: 2, Content: "(No items saved, save one with F10 on the headlines)", Color: go3270.Yellow})
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"errormsg": s.msg}
+
+	resp, err := go3270.HandleScreen(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		4, 1,            // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		del := make(map[int]bool)
+		for i := s.page; i < len(saved) && i < s.page+savedRows; i++ {
+			switch cmd := strings.ToUpper(strings.TrimSpace(resp.Values[fmt.Sprintf("cmd%d", i)])); cmd {
+			case "":
+			case "D":
+				del[i] = true
+			default:
+				s.msg = "Unknown line command " + cmd
+				return s.again(rsssaved)
+			}
+		}
+		if len(del) > 0 {
+			if err := s.deleteSaved(del); err != nil {
+				s.msg = err.Error()
+			} else {
+				s.msg = fmt.Sprintf("%d deleted", len(del))
+			}
+			return s.again(rsssaved)
+		}
+		if i, ok := rows[resp.Row]; ok {
+			return s.openArticle(saved[i].Channel, saved[i].rssItem)
+		}
+		return s.again(rsssaved)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rsssaved)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF6:
+		// Write the saved items to a file
+		if name, err := s.exportSaved(); err != nil {
+			s.msg = err.Error()
+		} else {
+			s.msg = "Exported to " + name
+		}
+		return s.again(rsssaved)
+	case go3270.AIDPF7:
+		s.page -= savedRows
+		if s.page < 0 {
+			s.page = 0
+		}
+		return s.again(rsssaved)
+	case go3270.AIDPF8:
+		if s.page+savedRows < len(saved) {
+			s.page += savedRows
+		}
+		return s.again(rsssaved)
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rsssaved)
+	}
+}
@@ -0,0 +1,192 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/racingmars/go3270"
+)
+
+const (
+	searchRows = 16  // results shown per page
+	maxResults = 500 // results kept for a search
+)
+
+// searchKeys are the keys accepted on the rsssearch screen.
+var searchKeys = keyMap{Screen: "Search", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Search", Help: "Read the item under the cursor, or search for the words entered", Validate: true},
+	keyHelp,
+	keyReturn,
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show the previous page of results"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show the next page of results"},
+	keyExit,
+}}
+
+// searchItems returns the items whose title or description contain every
+// word of query, ignoring case, newest first by their publish date. It
+// searches the archive, or the current items of every channel when there is
+// no archive.
+func searchItems(log *slog.Logger, query string) ([]archivedItem, error) {
+	words := strings.Fields(strings.ToLower(query))
+	match := func(it archivedItem) bool {
+		text := strings.ToLower(it.Title + " " + plainText(it.Description))
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var out []archivedItem
+	if archive != nil {
+		found, err := findArchived(match)
+		if err != nil {
+			return nil, err
+		}
+		out = found
+	} else {
+		now := time.Now().UTC()
+		list := channelList()
+		urls := make([]string, 0, len(list))
+		for _, c := range list {
+			urls = append(urls, c.URL)
+		}
+		fetchChannels(log, urls, func(url string, items []rssItem, err error) {
+			for _, it := range items {
+				if a := newArchivedItem(it, url, now); match(a) {
+					out = append(out, a)
+				}
+			}
+		})
+		sortArchived(out)
+	}
+	if len(out) > maxResults {
+		out = out[:maxResults]
+	}
+	return out, nil
+}
+
+func rsssearch(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := searchKeys
+
+	if s.page >= len(s.results) {
+		s.page = 0
+	}
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Search"
+	header := padCenter(title, 79)
+
+	//Header
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 2, Col: 0, Content: "Search for:"},
+		go3270.Field{Row: 2, Col: 12, Name: "query", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 2, Col: 79, Autoskip: true}, // field "stop" character
+	)
+	if s.query != "" {
+		screen = append(screen, go3270.Field{Row: 3, Col: 0, Content: fmt.Sprintf("%d items found", len(s.results)), Color: go3270.Blue})
+	}
+
+	// One line per item, with the date, the channel and the title
+	row := 4
+	rows := make(map[int]int) // screen row -> result
+	for i := s.page; i < len(s.results) && i < s.page+searchRows; i++ {
+		it := s.results[i]
+		color := go3270.White
+		if s.isRead(it.rssItem) {
+			color = go3270.Blue
+		}
+		line := fmt.Sprintf("%3d. %s %-14.14s %s", i+1, s.date(it.when()), channelName(it.Channel), it.Title)
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: max80(line, 80)[0], Color: color})
+		rows[row] = i
+		row++
+	}
+	if s.query != "" && len(s.results) == 0 {
+		screen = append(screen, go3270.Field{Row: row, Col: 0, Content: "(No items found)", Color: go3270.Yellow})
+	}
+
+	//Footer
+	screen = append(screen, go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true})
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := map[string]string{"query": s.query, "errormsg": s.msg}
+
+	resp, err := go3270.HandleScreen(
+		screen,          // the screen to display
+		nil,             // (no) rules to enforce
+		fieldValues,     // pre-populated values in fields
+		keys.pfkeys(),   // keys we accept -- validating
+		keys.exitkeys(), // keys we accept -- non-validating
+		"errormsg",      // name of field to put error messages in
+		2, 13,           // cursor coordinates
+		conn, // network connection
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.msg = ""
+
+	switch resp.AID {
+	case go3270.AIDEnter:
+		// Open the item under the cursor, or search
+		if i, ok := rows[resp.Row]; ok {
+			return s.openArticle(s.results[i].Channel, s.results[i].rssItem)
+		}
+		query := strings.TrimSpace(resp.Values["query"])
+		if query == "" {
+			s.msg = "Enter the words to search for"
+			return s.again(rsssearch)
+		}
+		results, err := searchItems(s.log, query)
+		if err != nil {
+			s.msg = fmt.Sprintf("Error searching: %v", err)
+		}
+		s.query, s.results, s.page = query, results, 0
+		return s.again(rsssearch)
+	case go3270.AIDPF1:
+		// Show the keys for this screen
+		return s.help(keys, rsssearch)
+	case go3270.AIDPF3:
+		// Previous screen
+		return s.back()
+	case go3270.AIDPF7:
+		s.page -= searchRows
+		if s.page < 0 {
+			s.page = 0
+		}
+		return s.again(rsssearch)
+	case go3270.AIDPF8:
+		if s.page+searchRows < len(s.results) {
+			s.page += searchRows
+		}
+		return s.again(rsssearch)
+	case go3270.AIDPF9:
+		// Exit
+		return s.exit()
+	default:
+		// re-run current transaction
+		return s.again(rsssearch)
+	}
+}
@@ -0,0 +1,102 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"net"
+	"strings"
+
+	"github.com/racingmars/go3270"
+)
+
+// signonKeys are the keys accepted on the rsssignon screen.
+var signonKeys = keyMap{Screen: "Sign on", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Sign on", Help: "Sign on, or continue without a user ID if it is blank", Validate: true},
+	keyHelp,
+	keyExit,
+}}
+
+// maxSignonAttempts is the number of wrong passwords before disconnecting.
+const maxSignonAttempts = 3
+
+func rsssignon(conn net.Conn, devinfo go3270.DevInfo, data any) (
+	go3270.Tx, any, error) {
+
+	s := data.(*session)
+
+	keys := signonKeys
+
+	// Make a local copy of the screen definition that we can append lines to.
+	screen := make(go3270.Screen, len(layout))
+	copy(screen, layout)
+
+	title := "Sign on"
+	header := padCenter(title, 79)
+
+	screen = append(screen,
+		go3270.Field{Row: 0, Col: 0, Content: header, Color: go3270.White, Intense: true},
+		go3270.Field{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		go3270.Field{Row: 4, Col: 10, Content: "User ID . . ."},
+		go3270.Field{Row: 4, Col: 24, Name: "userid", Write: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 4, Col: 45, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 5, Col: 10, Content: "Password  . ."},
+		go3270.Field{Row: 5, Col: 24, Name: "password", Write: true, Hidden: true, Highlighting: go3270.Underscore},
+		go3270.Field{Row: 5, Col: 45, Autoskip: true}, // field "stop" character
+		go3270.Field{Row: 8, Col: 10, Content: "Leave the user ID blank to continue without signing on.", Color: go3270.Blue},
+		go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true},
+	)
+	screen = append(screen, keys.footer()...)
+
+	fieldValues := make(map[string]string)
+
+	for {
+		resp, err := go3270.HandleScreen(
+			screen,          // the screen to display
+			nil,             // (no) rules to enforce
+			fieldValues,     // pre-populated values in fields
+			keys.pfkeys(),   // keys we accept -- validating
+			keys.exitkeys(), // keys we accept -- non-validating
+			"errormsg",      // name of field to put error messages in
+			4, 25,           // cursor coordinates
+			conn, // network connection
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch resp.AID {
+		case go3270.AIDEnter:
+			id := strings.TrimSpace(resp.Values["userid"])
+			if id == "" {
+				// Continue without signing on
+				return s.again(s.start())
+			}
+			if name, u, ok := signOn(id, resp.Values["password"]); ok {
+				s.log.Info("sign on", "user", name)
+				return s.signOn(name, u)
+			}
+			s.log.Warn("sign on failed", "user", id, "attempt", s.attempts+1)
+			s.attempts++
+			if s.attempts >= maxSignonAttempts {
+				return s.exit()
+			}
+			fieldValues = map[string]string{
+				"userid":   id,
+				"errormsg": "Invalid user ID or password",
+			}
+		case go3270.AIDPF1:
+			// Show the keys for this screen
+			return s.help(keys, rsssignon)
+		case go3270.AIDPF9:
+			// Exit
+			return s.exit()
+		}
+	}
+}
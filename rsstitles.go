@@ -17,20 +17,94 @@ import (
 	"github.com/racingmars/go3270"
 )
 
-func rsstitles(conn net.Conn, devinfo go3270.DevInfo, rssFeedURL any) (
+// titlesKeys are the keys accepted on the rsstitles screen.
+var titlesKeys = keyMap{Screen: "Change channel", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Select", Help: "Switch to the URL entered, the channel under the cursor or the channel number", Validate: true},
+	keyHelp,
+	{AID: go3270.AIDPF2, Label: "URLs", Help: "Show the urls of the channels"},
+	{AID: go3270.AIDPF3, Label: "Return", Help: "Return to the previous screen without changing channel"},
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show the previous page of channels"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show the next page of channels"},
+	keyExit,
+	keyRiver,
+	keySearch,
+	keySaved,
+}}
+
+// titleRows is the number of channels shown per page on the rsstitles and
+// rssurl screens, when every channel takes one row.
+const titleRows = 17
+
+// keyManage is offered to clients allowed to manage the channel list.
+var keyManage = key{AID: go3270.AIDPF6, Label: "Manage", Help: "Add, delete and reorder channels"}
+
+// keyGroups returns from the channels of a group to the list of groups.
+var keyGroups = key{AID: go3270.AIDPF4, Label: "Groups", Help: "Show all groups of channels"}
+
+// titleEntry is a line of the rsstitles screen, a group or a channel.
+type titleEntry struct {
+	Text  string
+	Group *group
+	URL   string
+}
+
+// titleEntries returns the lines of the rsstitles screen. With groups the
+// screen first lists the groups and the channels in no group, and then the
+// channels of the group selected.
+func (s *session) titleEntries() []titleEntry {
+	groups, ungrouped := channelGroups(s.channelList())
+	out := []titleEntry{}
+	if s.group != "" {
+		for _, g := range groups {
+			if g.Name != s.group {
+				continue
+			}
+			for _, c := range g.Channels {
+				out = append(out, titleEntry{Text: c.Title, URL: c.URL})
+			}
+		}
+		if len(out) > 0 {
+			return out
+		}
+		// The group is gone, or none of its channels are subscribed
+		s.group = ""
+	}
+	for i, g := range groups {
+		out = append(out, titleEntry{Text: fmt.Sprintf("%s (%d channels)", g.Name, len(g.Channels)), Group: &groups[i]})
+	}
+	for _, c := range ungrouped {
+		out = append(out, titleEntry{Text: c.Title, URL: c.URL})
+	}
+	return out
+}
+
+func rsstitles(conn net.Conn, devinfo go3270.DevInfo, data any) (
 	go3270.Tx, any, error) {
 
-	currentURL := rssFeedURL.(string)
+	s := data.(*session)
+
+	keys := titlesKeys
+	if s.isAdmin(conn) {
+		keys = keys.with(keyManage)
+	}
+	if s.userID != "" {
+		keys = keys.with(keyProfile)
+	}
 
-	// Accept Enter; PF3 exit.
-	pfkeys := []go3270.AID{go3270.AIDEnter, go3270.AIDPF2, go3270.AIDPF3}
-	exitkeys := []go3270.AID{go3270.AIDPF9}
+	entries := s.titleEntries()
+	prompt := "Or select from one of the below channels:"
+	title := "Change channel"
+	if s.group != "" {
+		keys = keys.with(keyGroups)
+		title += " - " + s.group
+	} else if len(entries) > 0 && entries[0].Group != nil {
+		prompt = "Or select a group or a channel:"
+	}
 
 	// Make a local copy of the screen definition that we can append lines to.
 	screen := make(go3270.Screen, len(layout))
 	copy(screen, layout)
 
-	title := "Change channel"
 	header := padCenter(title, 79)
 
 	//Header
@@ -40,88 +114,135 @@ func rsstitles(conn net.Conn, devinfo go3270.DevInfo, rssFeedURL any) (
 		go3270.Field{Row: 2, Col: 0, Content: "Enter URL:"},
 		go3270.Field{Row: 2, Col: 11, Name: "newURL", Write: true, Highlighting: go3270.Underscore},
 		go3270.Field{Row: 2, Col: 79, Autoskip: true}, // field "stop" character
-		go3270.Field{Row: 3, Col: 0, Content: "Or select from one of the below channels:"},
-		go3270.Field{Row: 3, Col: 42, Write: true, Name: "choice", Content: "0", Color: go3270.Turquoise},
+		go3270.Field{Row: 3, Col: 0, Content: prompt},
+		go3270.Field{Row: 3, Col: 42, Write: true, Name: "choice", Content: "0", Color: go3270.Turquoise, NumericOnly: true},
+		go3270.Field{Row: 3, Col: 45, Autoskip: true}, // field "stop" character
 	)
 
-	// Build list of RSS titles
+	// Build list of RSS titles, from the page shown
+	if s.page >= len(entries) {
+		s.page = 0
+	}
 	row := 4
-
-	var i int
-	for i = 0; i < len(rssChannels); i++ {
-		for _, line := range max80(fmt.Sprintf("%2d. %s", i, rssChannels[i][0]), 80) {
-			if rssChannels[i][0] == "" {
-				break
-			}
-			screen = append(screen, go3270.Field{Row: row, Col: 0, Content: line, Color: go3270.Yellow})
-			row++
+	next := s.page            // first entry of the next page
+	rows := make(map[int]int) // screen row -> entry
+	for i := s.page; i < len(entries); i++ {
+		e := entries[i]
+		color := go3270.Yellow
+		if e.Group != nil {
+			color = go3270.Turquoise
 		}
-		if row >= 22 {
+		lines := max80(fmt.Sprintf("%2d. %s", i, e.Text), 80)
+		if row+len(lines) > 21 {
 			break
 		}
+		for _, line := range lines {
+			screen = append(screen, go3270.Field{Row: row, Col: 0, Content: line, Color: color})
+			rows[row] = i
+			row++
+		}
+		next = i + 1
 	}
 	//Footer
 	screen = append(screen,
-		go3270.Field{Row: 22, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
-		go3270.Field{Row: 23, Col: 0, Content: "Enter", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 6, Content: "Save & return", Color: go3270.Blue},
-		go3270.Field{Row: 23, Col: 22, Content: "F2", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 25, Content: "URLs", Color: go3270.Blue},
-		go3270.Field{Row: 23, Col: 45, Content: "F3", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 48, Content: "Return", Color: go3270.Blue},
-		go3270.Field{Row: 23, Col: 69, Content: "F9", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 72, Content: "Exit", Color: go3270.Blue},
+		go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true},
 	)
+	screen = append(screen, keys.footer()...)
 
 	fieldValues := make(map[string]string)
+	rules := choiceRules(len(entries))
 
-	// We can call the old HandleScreen(), or we could have used the new
-	// HandleScreenAlt() and provided a nil DevInfo.
-	resp, err := go3270.HandleScreen(
-		screen,      // the screen to display
-		nil,         // (no) rules to enforce
-		fieldValues, // pre-populated values in fields
-		pfkeys,      // keys we accept -- validating
-		exitkeys,    // keys we accept -- non-validating
-		"errormsg",  // name of field to put error messages in
-		3, 43,       // cursor coordinates
-		conn, // network connection
-	)
-	if err != nil {
-		return nil, nil, err
-	}
+	for {
+		// We can call the old HandleScreen(), or we could have used the new
+		// HandleScreenAlt() and provided a nil DevInfo.
+		resp, err := go3270.HandleScreen(
+			screen,          // the screen to display
+			rules,           // rules to enforce
+			fieldValues,     // pre-populated values in fields
+			keys.pfkeys(),   // keys we accept -- validating
+			keys.exitkeys(), // keys we accept -- non-validating
+			"errormsg",      // name of field to put error messages in
+			3, 43,           // cursor coordinates
+			conn, // network connection
+		)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	switch resp.AID {
-	case go3270.AIDEnter:
-		fieldValues = resp.Values
-		if fieldValues["choice"] != "" {
-			ch := fieldValues["choice"]
-			var i int
-			if _, err := fmt.Sscanf(ch, "%2d", &i); err == nil {
-				//Do something with the error
+		switch resp.AID {
+		case go3270.AIDEnter:
+			fieldValues = resp.Values
+			if newURL := strings.TrimSpace(fieldValues["newURL"]); newURL != "" {
+				feeds, err := resolveFeed(s.log, newURL)
+				if err != nil {
+					// Stay on the screen and show what is wrong
+					fieldValues["errormsg"] = padRight(err.Error(), 79)
+					continue
+				}
+				return s.selectFeed(feeds)
 			}
-			if i < len(rssFeeds) {
-				currentURL = rssFeeds[i]
-			} else {
-				currentURL = rssFeeds[0]
+			var e *titleEntry
+			if i, ok := rows[resp.Row]; ok {
+				// Cursor placed on a group or channel
+				e = &entries[i]
+			} else if i := parseChoice(fieldValues["choice"]); i >= 0 {
+				e = &entries[i]
 			}
+			if e != nil && e.Group != nil {
+				return s.openGroup(*e.Group)
+			}
+			if e != nil {
+				s.setChannel(e.URL)
+			}
+			// Save and go back
+			return s.show(rssfeed)
+		case go3270.AIDPF1:
+			// Show the keys for this screen
+			return s.help(keys, rsstitles)
+		case go3270.AIDPF2:
+			// switch to URL screen
+			return s.show(rssurl)
+		case go3270.AIDPF6:
+			// Manage the channel list
+			return s.show(rsschannels)
+		case go3270.AIDPF3:
+			// Return without changing channel
+			return s.back()
+		case go3270.AIDPF10:
+			// Change the profile
+			return s.show(rssprofile)
+		case go3270.AIDPF7:
+			s.page -= titleRows
+			if s.page < 0 {
+				s.page = 0
+			}
+			return s.again(rsstitles)
+		case go3270.AIDPF8:
+			if next < len(entries) {
+				s.page = next
+			}
+			return s.again(rsstitles)
+		case go3270.AIDPF4:
+			// Back to the list of groups
+			return s.showGroup("", rsstitles)
+		case go3270.AIDPF13:
+			// The group under the cursor, or the channels listed, together
+			if i, ok := rows[resp.Row]; ok && entries[i].Group != nil {
+				return s.showGroup(entries[i].Group.Name, rssriver)
+			}
+			return s.show(rssriver)
+		case go3270.AIDPF11:
+			// Search all channels
+			return s.show(rsssearch)
+		case go3270.AIDPF12:
+			// Items saved to read later
+			return s.show(rsssaved)
+		case go3270.AIDPF9:
+			// Exit
+			return s.exit()
+		default:
+			// re-run current transaction
+			return s.again(rsstitles)
 		}
-		if strings.HasPrefix(strings.ToLower(fieldValues["newURL"]), "http") {
-			currentURL = fieldValues["newURL"]
-		}
-		// Save and go back
-		return rssfeed, currentURL, nil
-	case go3270.AIDPF2:
-		// switch to Title screen
-		return rssurl, currentURL, nil
-	case go3270.AIDPF3:
-		// Exit
-		return rssfeed, currentURL, nil
-	case go3270.AIDPF9:
-		// Exit
-		return nil, nil, nil
-	default:
-		// re-run current transaction
-		return rssfeed, currentURL, nil
 	}
 }
@@ -17,14 +17,23 @@ import (
 	"github.com/racingmars/go3270"
 )
 
-func rssurl(conn net.Conn, devinfo go3270.DevInfo, rssFeedURL any) (
+// urlKeys are the keys accepted on the rssurl screen.
+var urlKeys = keyMap{Screen: "Channel URLs", Keys: []key{
+	{AID: go3270.AIDEnter, Label: "Save & return", Help: "Switch to the URL entered, the channel under the cursor or the channel number", Validate: true},
+	keyHelp,
+	{AID: go3270.AIDPF2, Label: "Titles", Help: "Show the titles of the channels"},
+	{AID: go3270.AIDPF3, Label: "Return", Help: "Return to the previous screen without changing channel"},
+	{AID: go3270.AIDPF7, Label: "Up", Help: "Show the previous page of channels"},
+	{AID: go3270.AIDPF8, Label: "Down", Help: "Show the next page of channels"},
+	keyExit,
+}}
+
+func rssurl(conn net.Conn, devinfo go3270.DevInfo, data any) (
 	go3270.Tx, any, error) {
 
-	currentURL := rssFeedURL.(string)
+	s := data.(*session)
 
-	// Accept Enter; PF3 exit.
-	pfkeys := []go3270.AID{go3270.AIDEnter, go3270.AIDPF2, go3270.AIDPF3}
-	exitkeys := []go3270.AID{go3270.AIDPF9}
+	keys := urlKeys
 
 	// Make a local copy of the screen definition that we can append lines to.
 	screen := make(go3270.Screen, len(layout))
@@ -41,88 +50,107 @@ func rssurl(conn net.Conn, devinfo go3270.DevInfo, rssFeedURL any) (
 		go3270.Field{Row: 2, Col: 11, Name: "newURL", Write: true, Highlighting: go3270.Underscore},
 		go3270.Field{Row: 2, Col: 79, Autoskip: true}, // field "stop" character
 		go3270.Field{Row: 3, Col: 0, Content: "Or select from one of the below channels:"},
-		go3270.Field{Row: 3, Col: 42, Write: true, Name: "choice", Content: "0", Color: go3270.Turquoise},
+		go3270.Field{Row: 3, Col: 42, Write: true, Name: "choice", Content: "0", Color: go3270.Turquoise, NumericOnly: true},
+		go3270.Field{Row: 3, Col: 45, Autoskip: true}, // field "stop" character
 	)
 
-	// Build list of RSS Url's
+	// Build list of RSS Url's, from the page shown. A long url takes more
+	// than one row, so a page may show fewer than titleRows channels.
+	list := s.channelList()
+	if s.page >= len(list) {
+		s.page = 0
+	}
 	row := 4
-
-	for i, url := range rssFeeds {
-		for _, line := range wrap80(fmt.Sprintf("%2d. %s", i, url), 80) {
-			if row >= 22 { // leave space for footer/input
+	next := s.page            // first channel of the next page
+	rows := make(map[int]int) // screen row -> channel
+	for i := s.page; i < len(list); i++ {
+		lines := wrap80(fmt.Sprintf("%2d. %s", i, list[i].URL), 80)
+		if row+len(lines) > 21 { // leave space for footer/input
+			if i > s.page {
 				break
 			}
+			lines = lines[:21-row]
+		}
+		for _, line := range lines {
 			screen = append(screen, go3270.Field{Row: row, Col: 0, Content: line, Color: go3270.Yellow})
+			rows[row] = i
 			row++
 		}
-		if row >= 22 {
-			break
-		}
+		next = i + 1
 	}
 
 	//Footer
 	screen = append(screen,
-		go3270.Field{Row: 22, Col: 0, Content: strings.Repeat("-", 80), Color: go3270.Blue}, // ASCII only
-		go3270.Field{Row: 23, Col: 0, Content: "Enter", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 6, Content: "Save & return", Color: go3270.Blue},
-		go3270.Field{Row: 23, Col: 22, Content: "F2", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 25, Content: "Headlines", Color: go3270.Blue},
-		go3270.Field{Row: 23, Col: 45, Content: "F3", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 48, Content: "Return", Color: go3270.Blue},
-		go3270.Field{Row: 23, Col: 69, Content: "F9", Color: go3270.Turquoise},
-		go3270.Field{Row: 23, Col: 72, Content: "Exit", Color: go3270.Blue},
+		go3270.Field{Row: 21, Col: 0, Name: "errormsg", Color: go3270.Red, Intense: true},
 	)
+	screen = append(screen, keys.footer()...)
 
 	fieldValues := make(map[string]string)
+	rules := choiceRules(len(list))
+
+	for {
+		// We can call the old HandleScreen(), or we could have used the new
+		// HandleScreenAlt() and provided a nil DevInfo.
+		resp, err := go3270.HandleScreen(
+			screen,          // the screen to display
+			rules,           // rules to enforce
+			fieldValues,     // pre-populated values in fields
+			keys.pfkeys(),   // keys we accept -- validating
+			keys.exitkeys(), // keys we accept -- non-validating
+			"errormsg",      // name of field to put error messages in
+			3, 43,           // cursor coordinates
+			conn, // network connection
+		)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	// We can call the old HandleScreen(), or we could have used the new
-	// HandleScreenAlt() and provided a nil DevInfo.
-	resp, err := go3270.HandleScreen(
-		screen,      // the screen to display
-		nil,         // (no) rules to enforce
-		fieldValues, // pre-populated values in fields
-		pfkeys,      // keys we accept -- validating
-		exitkeys,    // keys we accept -- non-validating
-		"errormsg",  // name of field to put error messages in
-		3, 43,       // cursor coordinates
-		conn, // network connection
-	)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	switch resp.AID {
-	case go3270.AIDEnter:
-		fieldValues = resp.Values
-		if fieldValues["choice"] != "" {
-			ch := fieldValues["choice"]
-			var i int
-			if _, err := fmt.Sscanf(ch, "%2d", &i); err == nil {
-				//Do something with the error
+		switch resp.AID {
+		case go3270.AIDEnter:
+			fieldValues = resp.Values
+			if newURL := strings.TrimSpace(fieldValues["newURL"]); newURL != "" {
+				feeds, err := resolveFeed(s.log, newURL)
+				if err != nil {
+					// Stay on the screen and show what is wrong
+					fieldValues["errormsg"] = padRight(err.Error(), 79)
+					continue
+				}
+				return s.selectFeed(feeds)
 			}
-			if i < len(rssFeeds) {
-				currentURL = rssFeeds[i]
-			} else {
-				currentURL = rssFeeds[0]
+			if i, ok := rows[resp.Row]; ok {
+				// Cursor placed on a channel
+				s.setChannel(list[i].URL)
+			} else if i := parseChoice(fieldValues["choice"]); i >= 0 {
+				s.setChannel(list[i].URL)
 			}
-
-		}
-		if fieldValues["newURL"] != "" {
-			currentURL = fieldValues["newURL"]
+			// Save and go back
+			return s.show(rssfeed)
+		case go3270.AIDPF1:
+			// Show the keys for this screen
+			return s.help(keys, rssurl)
+		case go3270.AIDPF2:
+			// switch to Title screen
+			return s.show(rsstitles)
+		case go3270.AIDPF3:
+			// Return without changing channel
+			return s.back()
+		case go3270.AIDPF7:
+			s.page -= titleRows
+			if s.page < 0 {
+				s.page = 0
+			}
+			return s.again(rssurl)
+		case go3270.AIDPF8:
+			if next < len(list) {
+				s.page = next
+			}
+			return s.again(rssurl)
+		case go3270.AIDPF9:
+			// Exit
+			return s.exit()
+		default:
+			// re-run current transaction
+			return s.again(rssurl)
 		}
-		// Save and go back
-		return rssfeed, currentURL, nil
-	case go3270.AIDPF2:
-		// switch to Title screen
-		return rsstitles, currentURL, nil
-	case go3270.AIDPF3:
-		// Exit
-		return rssfeed, currentURL, nil
-	case go3270.AIDPF9:
-		// Exit
-		return nil, nil, nil
-	default:
-		// re-run current transaction
-		return rssfeed, currentURL, nil
 	}
 }
@@ -0,0 +1,239 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/racingmars/go3270"
+)
+
+// The open connections, with their session once the telnet negotiation is
+// done.
+var (
+	connsMu sync.Mutex
+	conns   = map[net.Conn]*session{}
+	connsWG sync.WaitGroup
+)
+
+// shuttingDown is set when the server stops, so sessions end at the next
+// key pressed.
+var shuttingDown atomic.Bool
+
+// serve accepts connections on ln and handles each in its own goroutine,
+// until ln is closed.
+func serve(ln net.Listener) {
+	var delay time.Duration
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// Out of file descriptors and the like, wait and try again
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else if delay < time.Second {
+				delay *= 2
+			}
+			slog.Error("accept", "err", err, "retry", delay)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+		reason := admit(conn)
+		countConnection(reason != "")
+		if reason != "" {
+			slog.Warn("connection refused", "client", conn.RemoteAddr().String(), "reason", reason)
+			conn.Close()
+			continue
+		}
+		connsWG.Add(1)
+		go func() {
+			defer connsWG.Done()
+			defer func() {
+				connsMu.Lock()
+				delete(conns, conn)
+				connsMu.Unlock()
+			}()
+			handle(conn)
+		}()
+	}
+}
+
+// openSessions returns the number of open connections.
+func openSessions() int {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	return len(conns)
+}
+
+// sessionInfo is what the admin interface shows of a session.
+type sessionInfo struct {
+	ID        string    `json:"id"`
+	Client    string    `json:"client"`
+	Terminal  string    `json:"terminal"`
+	Device    string    `json:"device,omitempty"`
+	Channel   string    `json:"channel"`
+	Connected time.Time `json:"connected"`
+}
+
+// sessionList returns the sessions open, oldest first. Connections still
+// negotiating are left out.
+func sessionList() []sessionInfo {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	out := []sessionInfo{}
+	for _, s := range conns {
+		if s == nil {
+			continue
+		}
+		out = append(out, sessionInfo{
+			ID:        s.id,
+			Client:    s.addr,
+			Terminal:  s.terminal,
+			Device:    s.device,
+			Channel:   s.showingChannel(),
+			Connected: s.connected,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Connected.Before(out[j].Connected) })
+	return out
+}
+
+// disconnect closes the connection of the session with ID id. It returns
+// false if there is no such session.
+func disconnect(id string) bool {
+	var found net.Conn
+	connsMu.Lock()
+	for conn, s := range conns {
+		if s != nil && s.id == id {
+			s.log.Info("disconnected by admin")
+			found = conn
+			break
+		}
+	}
+	connsMu.Unlock()
+	if found == nil {
+		return false
+	}
+	// Not under connsMu, closing a TLS connection can block
+	found.Close()
+	return true
+}
+
+// register records the session of conn.
+func register(conn net.Conn, s *session) {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	conns[conn] = s
+}
+
+// guard wraps tx so that the session ends instead of showing the next
+// screen once the server is shutting down. It also counts the screens
+// served.
+func guard(tx go3270.Tx) go3270.Tx {
+	return func(conn net.Conn, devinfo go3270.DevInfo, data any) (go3270.Tx, any, error) {
+		if shuttingDown.Load() {
+			return nil, nil, nil
+		}
+		countScreen(tx)
+		if s, ok := data.(*session); ok {
+			s.showing.Store(s.url)
+		}
+		next, data, err := tx(conn, devinfo, data)
+		if next == nil {
+			return nil, data, err
+		}
+		return guard(next), data, err
+	}
+}
+
+// shutdown tells every terminal that the server is shutting down, and waits
+// up to timeout for the sessions to end before closing their connections.
+// The listeners must already be closed.
+func shutdown(timeout time.Duration) {
+	shuttingDown.Store(true)
+
+	// Closing a TLS connection writes to it and can block, so the
+	// connections are closed after connsMu is released
+	var negotiating []net.Conn
+	connsMu.Lock()
+	for conn, s := range conns {
+		if s == nil {
+			// Still negotiating, nothing to show
+			negotiating = append(negotiating, conn)
+			continue
+		}
+		go showShutdown(conn, timeout)
+	}
+	connsMu.Unlock()
+	closeAll(negotiating)
+
+	done := make(chan struct{})
+	go func() {
+		connsWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return
+	case <-time.After(timeout):
+	}
+
+	connsMu.Lock()
+	open := slices.Collect(maps.Keys(conns))
+	connsMu.Unlock()
+	slog.Warn("closing sessions still open", "sessions", len(open))
+	closeAll(open)
+	select {
+	case <-done:
+	case <-time.After(httpTimeout):
+	}
+}
+
+// closeAll closes conns, at the same time so one stalled TLS peer does not
+// hold up the others.
+func closeAll(conns []net.Conn) {
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+}
+
+// showShutdown writes the shutdown screen to conn, without waiting for an
+// answer. The session reads the next key pressed and ends.
+func showShutdown(conn net.Conn, timeout time.Duration) {
+	screen := go3270.Screen{
+		{Row: 0, Col: 0, Content: padCenter("Server shutting down", 79), Color: go3270.White, Intense: true},
+		{Row: 1, Col: 0, Content: strings.Repeat("-", 79), Color: go3270.Blue}, // ASCII only
+		{Row: 4, Col: 10, Content: "rss3270cli is shutting down.", Color: go3270.Yellow, Intense: true},
+		{Row: 6, Col: 10, Content: "Press Enter to disconnect.", Color: go3270.Turquoise},
+		{Row: 7, Col: 10, Content: fmt.Sprintf("The session ends in %s.", timeout), Color: go3270.Turquoise},
+	}
+	if _, err := go3270.ShowScreenOpts(screen, nil, conn, go3270.ScreenOpts{NoResponse: true}); err != nil {
+		slog.Debug("shutdown screen", "client", conn.RemoteAddr().String(), "err", err)
+	}
+}
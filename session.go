@@ -0,0 +1,337 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/racingmars/go3270"
+)
+
+// session is the state of one terminal connection. It is the data passed
+// between transactions by go3270.RunTransactions, so anything that must
+// survive from one screen to the next lives here.
+type session struct {
+	id        string       // session ID in the log
+	log       *slog.Logger // logger adding the session ID
+	connected time.Time    // when the terminal connected
+	addr      string       // address of the terminal
+	showing   atomic.Value // url of the channel when the last screen was shown, for the admin interface
+
+	cur   go3270.Tx // transaction currently shown
+	url   string    // url of the current channel
+	group string    // channel group shown, empty for all channels
+	page  int       // first entry shown on paged screens
+	msg   string    // message to show on the next screen
+
+	history []visit  // screens to return to with PF3, latest last
+	recent  []string // urls of channels viewed before, latest first
+
+	item    rssItem        // item shown on the rssarticle screen
+	itemURL string         // and the channel it is from
+	feeds   []feedLink     // feeds offered on the rssdiscover screen
+	query   string         // last search on the rsssearch screen
+	results []archivedItem // and the items found
+
+	helpKeys keyMap    // keys of the screen help was asked for on
+	helpBack go3270.Tx // and the transaction to return to
+
+	terminal string          // terminal type negotiated
+	device   string          // TN3270E device name, empty for none
+	read     map[string]bool // keys of the items read
+
+	userID   string  // signed on user, empty for none
+	admin    bool    // signed on user may manage channels
+	prof     profile // profile of the signed on user
+	attempts int     // failed sign on attempts
+}
+
+// visit is a screen in the navigation history, with the channel, group
+// and page it showed.
+type visit struct {
+	tx    go3270.Tx
+	url   string
+	group string
+	page  int
+}
+
+const (
+	maxHistory = 20  // screens remembered for PF3
+	maxRecent  = 8   // channels in the recent list
+	maxRead    = 500 // read marks kept in a profile
+)
+
+// newSession returns the state for a new connection from addr showing url.
+func newSession(addr, url string) *session {
+	id := newSessionID()
+	return &session{
+		id:        id,
+		log:       slog.Default().With("session", id),
+		connected: time.Now(),
+		addr:      addr,
+		url:       url,
+	}
+}
+
+// first returns the first transaction of the session, the sign on screen
+// when there is a users file.
+func (s *session) first() go3270.Tx {
+	s.cur = s.start()
+	if usersFile != "" {
+		s.cur = rsssignon
+	}
+	return s.cur
+}
+
+// show goes to tx, starting on its first page. The current screen is
+// remembered so back can return to it.
+func (s *session) show(tx go3270.Tx) (go3270.Tx, any, error) {
+	s.remember()
+	s.cur = tx
+	s.page = 0
+	return tx, s, nil
+}
+
+// remember adds the current screen to the history.
+func (s *session) remember() {
+	s.history = append(s.history, visit{tx: s.cur, url: s.url, group: s.group, page: s.page})
+	if len(s.history) > maxHistory {
+		s.history = s.history[1:]
+	}
+}
+
+// showGroup goes to tx for the channels of group name, "" for all
+// channels.
+func (s *session) showGroup(name string, tx go3270.Tx) (go3270.Tx, any, error) {
+	s.remember()
+	s.cur, s.group, s.page = tx, name, 0
+	return tx, s, nil
+}
+
+// openGroup shows what selecting group g shows: its river, its default
+// channel or its list of channels.
+func (s *session) openGroup(g group) (go3270.Tx, any, error) {
+	switch {
+	case g.Open == "river":
+		return s.showGroup(g.Name, rssriver)
+	case g.Open == "default" && len(g.Channels) > 0:
+		s.remember()
+		s.setChannel(g.Channels[0].URL)
+		s.cur, s.group = s.start(), g.Name
+		return s.cur, s, nil
+	}
+	return s.showGroup(g.Name, rsstitles)
+}
+
+// again runs tx again, keeping the page.
+func (s *session) again(tx go3270.Tx) (go3270.Tx, any, error) {
+	s.cur = tx
+	return tx, s, nil
+}
+
+// back returns to the previous screen, with the channel and page it
+// showed. With no history it goes to the headlines.
+func (s *session) back() (go3270.Tx, any, error) {
+	if len(s.history) == 0 {
+		return s.again(rssfeed)
+	}
+	v := s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	s.cur, s.url, s.group, s.page = v.tx, v.url, v.group, v.page
+	return v.tx, s, nil
+}
+
+// exit ends the session.
+func (s *session) exit() (go3270.Tx, any, error) {
+	return nil, nil, nil
+}
+
+// showingChannel returns the url of the channel when the last screen was
+// shown. Unlike url it may be read from outside the session.
+func (s *session) showingChannel() string {
+	url, _ := s.showing.Load().(string)
+	return url
+}
+
+// setChannel switches the session to the channel at url. The channel left
+// is added to the recent list, and the group is left if the channel is not
+// in it.
+func (s *session) setChannel(url string) {
+	if url != s.url {
+		recent := []string{s.url}
+		for _, r := range s.recent {
+			if r != s.url && r != url && len(recent) < maxRecent {
+				recent = append(recent, r)
+			}
+		}
+		s.recent = recent
+	}
+	s.url = url
+	s.page = 0
+	s.log.Info("channel", "url", url, "name", channelName(url))
+	if s.group != "" && channelGroup(url) != s.group {
+		s.group = ""
+	}
+}
+
+// openArticle shows item of the channel at url on the rssarticle screen
+// and marks it read.
+func (s *session) openArticle(url string, item rssItem) (go3270.Tx, any, error) {
+	s.item = item
+	s.itemURL = url
+	s.markRead(item)
+	return s.show(rssarticle)
+}
+
+// help shows the keys of the current screen, returning to back.
+func (s *session) help(keys keyMap, back go3270.Tx) (go3270.Tx, any, error) {
+	s.helpKeys = keys
+	s.helpBack = back
+	return rsshelp, s, nil
+}
+
+// signOn starts the session of user id with the settings of its profile.
+func (s *session) signOn(id string, u user) (go3270.Tx, any, error) {
+	s.userID = id
+	s.admin = u.Admin
+	s.useProfile(u.Profile)
+	return s.again(s.start())
+}
+
+// identify records the terminal type and device name of the terminal, and
+// takes the profile kept for the device.
+func (s *session) identify(devinfo go3270.DevInfo, device string) {
+	s.terminal = terminalType(devinfo)
+	s.device = device
+	if s.device != "" && devicesFile != "" {
+		s.useProfile(deviceProfile(s.device))
+	}
+}
+
+// useProfile makes p the profile of the session. The session starts on
+// the default channel of the profile, or else the channel last viewed.
+func (s *session) useProfile(p profile) {
+	s.prof = p
+	s.read = make(map[string]bool)
+	for _, k := range p.Read {
+		s.read[k] = true
+	}
+	switch {
+	case p.DefaultChannel != "":
+		s.url = p.DefaultChannel
+	case p.LastChannel != "":
+		s.url = p.LastChannel
+	}
+}
+
+// saveProfile stores the profile of the signed on user, or else of the
+// device, with the channel viewed last.
+func (s *session) saveProfile() error {
+	s.prof.LastChannel = s.url
+	switch {
+	case s.userID != "":
+		return saveProfile(s.userID, s.prof)
+	case s.device != "" && devicesFile != "":
+		return saveDeviceProfile(s.device, s.prof)
+	}
+	return nil
+}
+
+// markRead remembers that item has been read.
+func (s *session) markRead(item rssItem) {
+	k := itemKey(item)
+	if s.read == nil {
+		s.read = make(map[string]bool)
+	}
+	if s.read[k] {
+		return
+	}
+	s.read[k] = true
+	s.prof.Read = append(s.prof.Read, k)
+	if len(s.prof.Read) > maxRead {
+		delete(s.read, s.prof.Read[0])
+		s.prof.Read = s.prof.Read[1:]
+	}
+}
+
+// isRead reports whether item has been read.
+func (s *session) isRead(item rssItem) bool {
+	return s.read[itemKey(item)]
+}
+
+// feedItems returns the items of the current channel to show as headlines,
+// after the filters of the channel and the profile, and the number of items
+// the filters hid.
+func (s *session) feedItems() ([]rssItem, int, error) {
+	items, err := fetchItems(s.log, s.url)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, hidden := applyFilters(items, channelFilter(s.url), s.prof.Filters[s.url])
+	if s.newestFirst() {
+		sortNewest(items)
+	}
+	if len(items) > maxHeadlines {
+		items = items[:maxHeadlines]
+	}
+	return items, hidden, nil
+}
+
+// newestFirst reports whether headlines are sorted by date, as set in the
+// profile or else by -newest.
+func (s *session) newestFirst() bool {
+	switch s.prof.Sort {
+	case "date":
+		return true
+	case "feed":
+		return false
+	}
+	return newestFirst
+}
+
+// start returns the headline screen the session prefers.
+func (s *session) start() go3270.Tx {
+	if s.prof.Links {
+		return rssfeedlinks
+	}
+	return rssfeed
+}
+
+// channelList returns the channels to offer, the subscribed channels of the
+// profile or all channels.
+func (s *session) channelList() []channel {
+	list := channelList()
+	if len(s.prof.Channels) == 0 {
+		return list
+	}
+	subscribed := make(map[string]bool)
+	for _, url := range s.prof.Channels {
+		subscribed[url] = true
+	}
+	out := []channel{}
+	for _, c := range list {
+		if subscribed[c.URL] {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return list
+	}
+	return out
+}
+
+// isAdmin reports whether the session may manage channels, either as a
+// signed on administrator or by the address it connects from.
+func (s *session) isAdmin(conn net.Conn) bool {
+	return s.admin || isAdmin(conn)
+}
@@ -0,0 +1,180 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// profile holds what is remembered for a user, or for a terminal without
+// a signed on user, between connections.
+type profile struct {
+	DefaultChannel string            `json:"defaultChannel,omitempty"`
+	Channels       []string          `json:"channels,omitempty"` // subscribed channel urls, none means all
+	Links          bool              `json:"links,omitempty"`    // start on the headline links screen
+	Sort           string            `json:"sort,omitempty"`     // "date" or "feed", empty for the server default
+	Zone           string            `json:"zone,omitempty"`     // time zone name, empty for the server default
+	DateFormat     string            `json:"dateFormat,omitempty"`
+	Saved          []bookmark        `json:"saved,omitempty"` // items saved to read later, oldest first
+	LastChannel    string            `json:"lastChannel,omitempty"`
+	Read           []string          `json:"read,omitempty"`    // keys of the items read, oldest first
+	Filters        map[string]filter `json:"filters,omitempty"` // by channel url
+}
+
+// clone returns a copy of p that shares no slices or maps with it, so a
+// session can append to its copy while other sessions of the same user or
+// terminal use theirs.
+func (p profile) clone() profile {
+	p.Channels = slices.Clone(p.Channels)
+	p.Saved = slices.Clone(p.Saved)
+	p.Read = slices.Clone(p.Read)
+	p.Filters = maps.Clone(p.Filters)
+	return p
+}
+
+// user is an entry in the users file. The password is stored as a salted
+// PBKDF2 hash.
+type user struct {
+	Salt    string  `json:"salt"`
+	Hash    string  `json:"hash"`
+	Admin   bool    `json:"admin,omitempty"`
+	Profile profile `json:"profile"`
+}
+
+const (
+	hashIterations = 100000
+	hashLength     = 32
+)
+
+// usersFile is the users file given with -users. Sign on is only offered
+// when it is set.
+var usersFile string
+
+var (
+	usersMu sync.Mutex
+	users   = map[string]*user{}
+)
+
+// loadUsers reads the users file. A missing file is an empty user list.
+func loadUsers() error {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	content, err := os.ReadFile(usersFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, &users)
+}
+
+// saveUsers writes the users file. The caller must hold usersMu.
+func saveUsers() error {
+	content, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := usersFile + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, usersFile)
+}
+
+func hashPassword(password string, salt []byte) (string, error) {
+	h, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, hashLength)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h), nil
+}
+
+// setPassword creates the user id, or changes the password of an existing
+// user, and saves the users file. admin makes the user an administrator, an
+// existing administrator stays one without it.
+func setPassword(id, password string, admin bool) error {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if id == "" || password == "" {
+		return errors.New("user ID and password must not be empty")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	hash, err := hashPassword(password, salt)
+	if err != nil {
+		return err
+	}
+
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	u := users[id]
+	if u == nil {
+		u = &user{}
+		users[id] = u
+	}
+	u.Salt = hex.EncodeToString(salt)
+	u.Hash = hash
+	if admin {
+		u.Admin = true
+	}
+	return saveUsers()
+}
+
+// signOn checks the password of user id. It returns the normalised user ID
+// and a copy of the user.
+func signOn(id, password string) (string, user, bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	usersMu.Lock()
+	p := users[id]
+	var u user
+	if p != nil {
+		// Copied under the lock, saveProfile changes it
+		u = *p
+		u.Profile = p.Profile.clone()
+	}
+	usersMu.Unlock()
+	if p == nil {
+		return "", user{}, false
+	}
+	salt, err := hex.DecodeString(u.Salt)
+	if err != nil {
+		return "", user{}, false
+	}
+	hash, err := hashPassword(password, salt)
+	if err != nil || subtle.ConstantTimeCompare([]byte(hash), []byte(u.Hash)) != 1 {
+		return "", user{}, false
+	}
+	return id, u, true
+}
+
+// saveProfile stores the profile of user id in the users file.
+func saveProfile(id string, p profile) error {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	u := users[id]
+	if u == nil {
+		return errors.New("unknown user " + id)
+	}
+	u.Profile = p
+	return saveUsers()
+}
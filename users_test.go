@@ -0,0 +1,49 @@
+// This file is part of https://github.com/MortenHarding/rss3270cli/
+// Copyright 2025 by Morten Harding, licensed under the MIT license. See
+// LICENSE in the project root for license information.
+
+// It is based on example5 of https://github.com/racingmars/go3270/
+// Copyright 2025 by Matthew R. Wilson
+// and the code in https://github.com/ErnieTech101/rss3270svr
+// Copyright ErnieTech101
+
+package main
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestSignOnConcurrent signs on while the profile is saved by another
+// session of the same user. Run it with -race.
+func TestSignOnConcurrent(t *testing.T) {
+	usersFile = filepath.Join(t.TempDir(), "users.json")
+	defer func() { usersFile = "" }()
+	if err := setPassword("morten", "secret", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, u, ok := signOn("morten", "secret")
+			if !ok {
+				t.Error("sign on failed")
+				return
+			}
+			// Sessions append to their copy of the profile
+			u.Profile.Read = append(u.Profile.Read, "item")
+		}()
+		go func() {
+			defer wg.Done()
+			p := profile{Read: make([]string, 1, 10)}
+			if err := saveProfile("MORTEN", p); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}